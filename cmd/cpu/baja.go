@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+const modoBajaDrenar = "DRENAR" // MODO_BAJA: termina la ráfaga en curso antes de cerrarse
+
+var (
+	// ejecucionMutex se toma durante cada instrucción; al cerrarse la CPU lo toma para no cortar una a la mitad
+	ejecucionMutex sync.Mutex
+
+	bajaCompletada     = make(chan struct{})
+	bajaCompletadaOnce sync.Once
+)
+
+// escucharSenalesDeSalida da de baja la CPU en el kernel al recibir SIGINT o SIGTERM. Con MODO_BAJA
+// "DRENAR" espera a que el kernel avise que terminó la ráfaga en curso; una segunda señal la cierra
+// sin esperar
+func escucharSenalesDeSalida() {
+	senales := make(chan os.Signal, 1)
+	signal.Notify(senales, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-senales
+		drenar := config.ModoBaja == modoBajaDrenar
+		utils.InfoLog.Info("Señal de salida recibida, dando de baja la CPU", "identificador", identificador, "drenar", drenar)
+
+		if solicitarBaja(drenar) {
+			utils.InfoLog.Info("Esperando que termine la ráfaga en curso")
+			select {
+			case <-bajaCompletada:
+			case <-senales:
+				utils.InfoLog.Warn("Segunda señal de salida, baja inmediata")
+				solicitarBaja(false)
+			}
+		}
+
+		ejecucionMutex.Lock()
+		utils.InfoLog.Info("CPU dada de baja", "identificador", identificador)
+		os.Exit(0)
+	}()
+}
+
+// solicitarBaja avisa al kernel y devuelve si la baja quedó pendiente de que termine la ráfaga en curso
+func solicitarBaja(drenar bool) bool {
+	datos := map[string]interface{}{
+		"nombre":        "CPU",
+		"tipo":          "CPU",
+		"identificador": identificador,
+		"drenar":        drenar,
+	}
+
+	respuesta, err := kernelClient.EnviarHTTPMensaje(utils.MensajeBaja, "default", datos)
+	if err != nil {
+		utils.ErrorLog.Error("No se pudo avisar la baja al Kernel", "error", err)
+		return false
+	}
+
+	respuestaMap, _ := respuesta.(map[string]interface{})
+	pendiente, _ := respuestaMap["pendiente"].(bool)
+	return pendiente
+}
+
+func manejarBajaCompletada(msg *utils.Mensaje) (interface{}, error) {
+	utils.InfoLog.Info("El Kernel confirmó la baja de la CPU", "origen", msg.Origen)
+	bajaCompletadaOnce.Do(func() { close(bajaCompletada) })
+	return map[string]interface{}{"status": "OK"}, nil
+}
@@ -33,6 +33,7 @@ var (
 	mutex                 sync.Mutex
 	interrupcionPendiente bool
 	pidInterrumpido       int
+	tidInterrumpido       int
 	procesoEnEjecucion    int = -1 // PID del proceso actualmente en ejecución
 )
 
@@ -96,11 +97,11 @@ func inicializarCache() {
 }
 
 // Implementar ciclo de instrucción completo
-func ejecutarCiclo(pid, pc int) (int, string, map[string]interface{}) {
+func ejecutarCiclo(pid, tid, pc int) (int, string, map[string]interface{}) {
 	procesoEnEjecucion = pid
 
 	// Fetch
-	instruccion := fetch(pid, pc)
+	instruccion := fetch(pid, tid, pc)
 	if instruccion == "" {
 		return pc, "ERROR", nil
 	}
@@ -108,18 +109,18 @@ func ejecutarCiclo(pid, pc int) (int, string, map[string]interface{}) {
 	// Decode y Execute
 	siguientePC, motivo, parametrosSyscall := decodeAndExecute(pid, pc, instruccion)
 
-	// Check Interrupt
-	if checkInterrupt(pid) {
-		limpiarEstructurasPorPID(pid)
-		procesoEnEjecucion = -1
-		return siguientePC, "INTERRUPTED", nil
-	}
-
 	// Si el PC no fue modificado por GOTO, incrementar
 	if siguientePC == pc && motivo == "" {
 		siguientePC = pc + 1
 	}
 
+	// Check Interrupt: si la instrucción ya devuelve el proceso (syscall), la interrupción se descarta
+	if checkInterrupt(pid, tid) && motivo == "" {
+		limpiarEstructurasPorPID(pid)
+		procesoEnEjecucion = -1
+		return siguientePC, "INTERRUPTED", nil
+	}
+
 	// Si hay motivo de retorno, el proceso debe salir de la CPU
 	if motivo != "" {
 		procesoEnEjecucion = -1
@@ -129,17 +130,25 @@ func ejecutarCiclo(pid, pc int) (int, string, map[string]interface{}) {
 }
 
 // Verificar interrupciones
-func checkInterrupt(pid int) bool {
+func checkInterrupt(pid, tid int) bool {
 	mutex.Lock()
 	defer mutex.Unlock()
 
-	if interrupcionPendiente && pidInterrumpido == pid {
-		utils.InfoLog.Info("Interrupción recibida al puerto Interrupt")
-		interrupcionPendiente = false
+	if !interrupcionPendiente {
+		return false
+	}
+
+	interrupcionPendiente = false
+	if pidInterrumpido != pid || tidInterrumpido != tid {
+		// La interrupción era para un proceso (o hilo) que ya dejó esta CPU
+		utils.InfoLog.Info("Interrupción obsoleta descartada", "pid_interrumpido", pidInterrumpido, "tid_interrumpido", tidInterrumpido, "pid_actual", pid, "tid_actual", tid)
 		pidInterrumpido = -1
-		return true
+		return false
 	}
-	return false
+
+	utils.InfoLog.Info("Interrupción recibida al puerto Interrupt")
+	pidInterrumpido = -1
+	return true
 }
 
 // Limpiar estructuras al desalojar proceso
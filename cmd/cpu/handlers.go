@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
@@ -13,6 +14,8 @@ func RegistrarHandlers() {
 	modulo.RegistrarHandler(fmt.Sprintf("%d", utils.MensajeOperacion), "EJECUTAR_PROCESO", manejarEjecutar)
 	modulo.RegistrarHandler(fmt.Sprintf("%d", utils.MensajeEjecutar), "default", manejarEjecutar)
 	modulo.RegistrarHandler(fmt.Sprintf("%d", utils.MensajeInterrupcion), "INTERRUPCION", manejarInterrupcion)
+	modulo.RegistrarHandler(fmt.Sprintf("%d", utils.MensajeBajaCompletada), "default", manejarBajaCompletada)
+	modulo.EstadoSalud = estadoSalud
 	
 	utils.InfoLog.Info("Handlers registrados correctamente")
 }
@@ -22,6 +25,25 @@ func manejarHandshake(msg *utils.Mensaje) (interface{}, error) {
     return map[string]interface{}{"status": "OK"}, nil
 }
 
+// ultimaInstruccion es el resultado de la última instrucción ejecutada. Se informa en /health para que,
+// si la CPU se cae antes de responder, el kernel retome el proceso desde el PC siguiente
+var (
+	ultimaInstruccion      map[string]interface{}
+	ultimaInstruccionMutex sync.Mutex
+)
+
+// estadoSalud agrega a /health el despacho, PID, TID y PC siguiente de la última instrucción ejecutada
+func estadoSalud() map[string]interface{} {
+	ultimaInstruccionMutex.Lock()
+	defer ultimaInstruccionMutex.Unlock()
+
+	estado := map[string]interface{}{}
+	for clave, valor := range ultimaInstruccion {
+		estado[clave] = valor
+	}
+	return estado
+}
+
 // Handler para ejecutar instrucción
 func manejarEjecutar(msg *utils.Mensaje) (interface{}, error) {
 	datos := msg.Datos.(map[string]interface{})
@@ -38,14 +60,28 @@ func manejarEjecutar(msg *utils.Mensaje) (interface{}, error) {
 	pidInt := int(pid)
 	pcInt := int(pc)
 
-	utils.InfoLog.Info("Proceso recibido para ejecutar", "pid", pidInt, "pc", pcInt)
+	// TID del hilo a ejecutar (0 = hilo principal)
+	tidInt := 0
+	if tid, hayTID := datos["tid"].(float64); hayTID {
+		tidInt = int(tid)
+	}
 
-	// Ejecutar ciclo de instrucción
-	siguientePC, motivo, parametrosSyscall := ejecutarCiclo(pidInt, pcInt)
+	utils.InfoLog.Info("Proceso recibido para ejecutar", "pid", pidInt, "tid", tidInt, "pc", pcInt)
+
+	// Ejecutar ciclo de instrucción. Al cerrarse, la CPU espera a que termine la instrucción en curso
+	ejecucionMutex.Lock()
+	siguientePC, motivo, parametrosSyscall := ejecutarCiclo(pidInt, tidInt, pcInt)
+	if despacho, hayDespacho := datos["despacho"].(float64); hayDespacho {
+		ultimaInstruccionMutex.Lock()
+		ultimaInstruccion = map[string]interface{}{"despacho": int(despacho), "pid": pidInt, "tid": tidInt, "pc": siguientePC}
+		ultimaInstruccionMutex.Unlock()
+	}
+	ejecucionMutex.Unlock()
 
 	// Preparar respuesta
 	respuesta := map[string]interface{}{
 		"pid": pidInt,
+		"tid": tidInt,
 		"pc":  siguientePC,
 	}
 
@@ -75,13 +111,18 @@ func manejarInterrupcion(msg *utils.Mensaje) (interface{}, error) {
 	}
 
 	pidInt := int(pid)
+	tidInt := 0
+	if tid, hayTID := datos["tid"].(float64); hayTID {
+		tidInt = int(tid)
+	}
 
 	mutex.Lock()
 	interrupcionPendiente = true
 	pidInterrumpido = pidInt
+	tidInterrumpido = tidInt
 	mutex.Unlock()
 
-	utils.InfoLog.Info("Interrupción configurada", "pid", pidInt)
+	utils.InfoLog.Info("Interrupción configurada", "pid", pidInt, "tid", tidInt)
 
 	return map[string]interface{}{"ok": true}, nil
 }
@@ -9,11 +9,12 @@ import (
 )
 
 // Fetch: Obtener instrucción desde memoria
-func fetch(pid, pc int) string {
-	utils.InfoLog.Info(fmt.Sprintf("PID: %d - FETCH - PC: %d", pid, pc))
+func fetch(pid, tid, pc int) string {
+	utils.InfoLog.Info(fmt.Sprintf("PID: %d - FETCH - PC: %d", pid, pc), "tid", tid)
 
 	params := map[string]interface{}{
 		"pid": pid,
+		"tid": tid,
 		"pc":  pc,
 	}
 
@@ -140,13 +141,218 @@ func decodeAndExecute(pid, pc int, instruccion string) (int, string, map[string]
 			}
 			parametrosSyscall["archivo"] = archivo
 			parametrosSyscall["tamano"] = tamano
+
+			// Parámetros opcionales: prioridad numérica y opciones CLAVE=VALOR (ej. TICKETS=50, PERIODO=1000)
+			opciones := map[string]interface{}{}
+			for _, extra := range parametros[2:] {
+				if clave, valor, esOpcion := strings.Cut(extra, "="); esOpcion {
+					numero, err := strconv.Atoi(valor)
+					if err != nil || numero < 0 {
+						utils.ErrorLog.Error("Error en opción INIT_PROC", "opcion", extra, "error", err)
+						motivoRetorno = "ERROR"
+						break
+					}
+					opciones[strings.ToUpper(clave)] = numero
+					continue
+				}
+
+				prioridad, err := strconv.Atoi(extra)
+				if err != nil || prioridad < 0 {
+					utils.ErrorLog.Error("Error en prioridad INIT_PROC", "valor", extra, "error", err)
+					motivoRetorno = "ERROR"
+					break
+				}
+				parametrosSyscall["prioridad"] = prioridad
+			}
+			if motivoRetorno == "ERROR" {
+				break
+			}
+			if len(opciones) > 0 {
+				parametrosSyscall["opciones"] = opciones
+			}
+
 			motivoRetorno = "SYSCALL_INIT_PROC"
-			utils.InfoLog.Info("INIT_PROC solicitado", "pid", pid, "archivo", archivo, "tamano", tamano)
+			utils.InfoLog.Info("INIT_PROC solicitado", "pid", pid, "archivo", archivo, "tamano", tamano, "prioridad", parametrosSyscall["prioridad"], "opciones", parametrosSyscall["opciones"])
 		} else {
 			utils.ErrorLog.Error("INIT_PROC: parámetros insuficientes", "parametros", parametros)
 			motivoRetorno = "ERROR"
 		}
 
+	case "WAIT":
+		if len(parametros) >= 1 {
+			// WAIT ANY espera a cualquier hijo, WAIT <pid> a uno en particular y WAIT <nombre> toma un recurso
+			if pidHijo, err := strconv.Atoi(parametros[0]); err == nil {
+				if pidHijo < 0 {
+					utils.ErrorLog.Error("Error en PID WAIT", "valor", parametros[0])
+					motivoRetorno = "ERROR"
+					break
+				}
+				parametrosSyscall["pid"] = pidHijo
+			} else if strings.ToUpper(parametros[0]) != "ANY" {
+				parametrosSyscall["recurso"] = parametros[0]
+			}
+			motivoRetorno = "SYSCALL_WAIT"
+			utils.InfoLog.Info("WAIT solicitado", "pid", pid, "objetivo", parametros[0])
+		} else {
+			utils.ErrorLog.Error("WAIT: parámetros insuficientes", "parametros", parametros)
+			motivoRetorno = "ERROR"
+		}
+
+	case "SIGNAL":
+		if len(parametros) >= 1 {
+			parametrosSyscall["recurso"] = parametros[0]
+			motivoRetorno = "SYSCALL_SIGNAL"
+			utils.InfoLog.Info("SIGNAL solicitado", "pid", pid, "recurso", parametros[0])
+		} else {
+			utils.ErrorLog.Error("SIGNAL: parámetros insuficientes", "parametros", parametros)
+			motivoRetorno = "ERROR"
+		}
+
+	case "CLAIM":
+		if len(parametros) >= 2 {
+			cantidad, err := strconv.Atoi(parametros[1])
+			if err != nil || cantidad < 0 {
+				utils.ErrorLog.Error("Error en cantidad CLAIM", "valor", parametros[1], "error", err)
+				motivoRetorno = "ERROR"
+				break
+			}
+			parametrosSyscall["recurso"] = parametros[0]
+			parametrosSyscall["cantidad"] = cantidad
+			motivoRetorno = "SYSCALL_CLAIM"
+			utils.InfoLog.Info("CLAIM solicitado", "pid", pid, "recurso", parametros[0], "cantidad", cantidad)
+		} else {
+			utils.ErrorLog.Error("CLAIM: parámetros insuficientes", "parametros", parametros)
+			motivoRetorno = "ERROR"
+		}
+
+	case "THREAD_CREATE":
+		if len(parametros) >= 1 {
+			parametrosSyscall["archivo"] = parametros[0]
+			if len(parametros) >= 2 {
+				prioridad, err := strconv.Atoi(parametros[1])
+				if err != nil || prioridad < 0 {
+					utils.ErrorLog.Error("Error en prioridad THREAD_CREATE", "valor", parametros[1], "error", err)
+					motivoRetorno = "ERROR"
+					break
+				}
+				parametrosSyscall["prioridad"] = prioridad
+			}
+			// Desde ahora Memoria informa las páginas del proceso como compartidas y no se cachean:
+			// lo modificado hasta acá se baja para que el hilo nuevo lo vea desde cualquier CPU
+			limpiarEstructurasPorPID(pid)
+			motivoRetorno = "SYSCALL_THREAD_CREATE"
+			utils.InfoLog.Info("THREAD_CREATE solicitado", "pid", pid, "archivo", parametros[0], "prioridad", parametrosSyscall["prioridad"])
+		} else {
+			utils.ErrorLog.Error("THREAD_CREATE: parámetros insuficientes", "parametros", parametros)
+			motivoRetorno = "ERROR"
+		}
+
+	case "THREAD_JOIN":
+		if len(parametros) >= 1 {
+			tid, err := strconv.Atoi(parametros[0])
+			if err != nil || tid < 0 {
+				utils.ErrorLog.Error("Error en TID THREAD_JOIN", "valor", parametros[0], "error", err)
+				motivoRetorno = "ERROR"
+				break
+			}
+			parametrosSyscall["tid"] = tid
+			motivoRetorno = "SYSCALL_THREAD_JOIN"
+			utils.InfoLog.Info("THREAD_JOIN solicitado", "pid", pid, "tid", tid)
+		} else {
+			utils.ErrorLog.Error("THREAD_JOIN: parámetros insuficientes", "parametros", parametros)
+			motivoRetorno = "ERROR"
+		}
+
+	case "THREAD_EXIT":
+		motivoRetorno = "SYSCALL_THREAD_EXIT"
+		utils.InfoLog.Info("THREAD_EXIT ejecutado", "pid", pid)
+
+	case "IPC_CREATE":
+		if len(parametros) >= 3 {
+			capacidad, err := strconv.Atoi(parametros[2])
+			if err != nil || capacidad <= 0 {
+				utils.ErrorLog.Error("Error en capacidad IPC_CREATE", "valor", parametros[2], "error", err)
+				motivoRetorno = "ERROR"
+				break
+			}
+			parametrosSyscall["nombre"] = parametros[0]
+			parametrosSyscall["tipo"] = strings.ToUpper(parametros[1])
+			parametrosSyscall["capacidad"] = capacidad
+			motivoRetorno = "SYSCALL_IPC_CREATE"
+			utils.InfoLog.Info("IPC_CREATE solicitado", "pid", pid, "nombre", parametros[0], "tipo", parametros[1], "capacidad", capacidad)
+		} else {
+			utils.ErrorLog.Error("IPC_CREATE: parámetros insuficientes", "parametros", parametros)
+			motivoRetorno = "ERROR"
+		}
+
+	case "IPC_OPEN", "IPC_CLOSE":
+		if len(parametros) >= 1 {
+			parametrosSyscall["nombre"] = parametros[0]
+			motivoRetorno = "SYSCALL_" + operacion
+			utils.InfoLog.Info(operacion+" solicitado", "pid", pid, "nombre", parametros[0])
+		} else {
+			utils.ErrorLog.Error(operacion+": parámetros insuficientes", "parametros", parametros)
+			motivoRetorno = "ERROR"
+		}
+
+	case "IPC_SEND", "IPC_RECEIVE":
+		if len(parametros) >= 3 {
+			direccion, err1 := strconv.Atoi(parametros[1])
+			tamano, err2 := strconv.Atoi(parametros[2])
+			if err1 != nil || err2 != nil || direccion < 0 || tamano <= 0 {
+				utils.ErrorLog.Error("Error en parámetros "+operacion, "err1", err1, "err2", err2)
+				motivoRetorno = "ERROR"
+				break
+			}
+			// El kernel copia los datos directamente en Memoria: las páginas modificadas en la cache
+			// se bajan antes y las que queden se descartan para no leer datos viejos después
+			limpiarEstructurasPorPID(pid)
+			parametrosSyscall["nombre"] = parametros[0]
+			parametrosSyscall["direccion"] = direccion
+			parametrosSyscall["tamanio"] = tamano
+			motivoRetorno = "SYSCALL_" + operacion
+			utils.InfoLog.Info(operacion+" solicitado", "pid", pid, "nombre", parametros[0], "direccion", direccion, "tamanio", tamano)
+		} else {
+			utils.ErrorLog.Error(operacion+": parámetros insuficientes", "parametros", parametros)
+			motivoRetorno = "ERROR"
+		}
+
+	case "SHM_ATTACH":
+		if len(parametros) >= 3 {
+			tamano, err1 := strconv.Atoi(parametros[1])
+			direccion, err2 := strconv.Atoi(parametros[2])
+			if err1 != nil || err2 != nil || direccion < 0 {
+				utils.ErrorLog.Error("Error en parámetros SHM_ATTACH", "err1", err1, "err2", err2)
+				motivoRetorno = "ERROR"
+				break
+			}
+			if err := adjuntarSegmento(pid, parametros[0], tamano, direccion); err != nil {
+				utils.ErrorLog.Error("Error en SHM_ATTACH", "pid", pid, "nombre", parametros[0], "error", err)
+				motivoRetorno = "ERROR"
+				break
+			}
+			utils.InfoLog.Info("SHM_ATTACH ejecutado", "pid", pid, "nombre", parametros[0], "direccion", direccion)
+		} else {
+			utils.ErrorLog.Error("SHM_ATTACH: parámetros insuficientes", "parametros", parametros)
+			motivoRetorno = "ERROR"
+		}
+
+	case "SHM_DETACH":
+		if len(parametros) >= 1 {
+			// Las páginas modificadas en la cache se bajan antes de quitar el mapeo y la TLB no
+			// puede conservar marcos que pasan a ser de otro proceso
+			limpiarEstructurasPorPID(pid)
+			if err := desadjuntarSegmento(pid, parametros[0]); err != nil {
+				utils.ErrorLog.Error("Error en SHM_DETACH", "pid", pid, "nombre", parametros[0], "error", err)
+				motivoRetorno = "ERROR"
+				break
+			}
+			utils.InfoLog.Info("SHM_DETACH ejecutado", "pid", pid, "nombre", parametros[0])
+		} else {
+			utils.ErrorLog.Error("SHM_DETACH: parámetros insuficientes", "parametros", parametros)
+			motivoRetorno = "ERROR"
+		}
+
 	case "DUMP_MEMORY":
 		motivoRetorno = "SYSCALL_DUMP_MEMORY"
 		utils.InfoLog.Info("DUMP_MEMORY solicitado", "pid", pid)
@@ -31,6 +31,7 @@ func main() {
 
 	// Inicializar componentes de la CPU
 	inicializarCPU()
+	escucharSenalesDeSalida()
 
 	// Mantener vivo el proceso
 	select {}
@@ -69,6 +70,11 @@ func inicializarModulo() {
 	utils.InicializarLogger(config.LogLevel, loggerName)
 	utils.InfoLog.Info("Configuración cargada", "nivel_log", config.LogLevel, "config_path", rutaConfig)
 
+	if err := utils.ConfigurarRelojCompartido(config.ClockMode, config.ClockFactor, config.IPKernel, config.PortKernel, config.IPCPU, config.PortCPU); err != nil {
+		utils.ErrorLog.Error("Configuración de reloj inválida", "error", err)
+		os.Exit(1)
+	}
+
 	// Datos para el handshake
 	datosHandshake := map[string]interface{}{
 		"nombre":        "CPU",
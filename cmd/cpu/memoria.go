@@ -194,7 +194,10 @@ func actualizarTLB(pid, numeroPagina, marco int) {
 	tlbCounter++
 }
 
-// Obtener marco de memoria para una página
+// Obtener marco de memoria para una página. Las páginas que Memoria informa como compartidas (de un
+// segmento compartido o de un proceso con hilos secundarios) no entran en la cache: la cache es
+// write-back y otro proceso u otro hilo escribiría la misma página en Memoria mientras tanto, así que
+// lecturas y escrituras de esas páginas van siempre directo a Memoria
 func obtenerMarcoDeMemoria(pid, numeroPagina int) int {
 	utils.InfoLog.Info("Buscando marco", "pid", pid, "pagina", numeroPagina)
 
@@ -219,7 +222,7 @@ func obtenerMarcoDeMemoria(pid, numeroPagina int) int {
 
 	// Simular delay de cache si está configurado
 	if config.CacheDelay > 0 {
-		time.Sleep(time.Duration(config.CacheDelay) * time.Millisecond)
+		utils.Dormir(time.Duration(config.CacheDelay) * time.Millisecond)
 	}
 
 	// Enviar solicitud a memoria
@@ -249,7 +252,10 @@ func obtenerMarcoDeMemoria(pid, numeroPagina int) int {
 	utils.InfoLog.Info(fmt.Sprintf("PID: %d - OBTENER MARCO - Página: %d - Marco: %d", pid, numeroPagina, marcoInt))
 
 	// Actualizar caché si está habilitada
-	if config.CacheEntries > 0 {
+	compartida, _ := datos["compartida"].(bool)
+	if config.CacheEntries > 0 && compartida {
+		utils.InfoLog.Info("Página compartida, no se cachea", "pid", pid, "pagina", numeroPagina, "marco", marcoInt)
+	} else if config.CacheEntries > 0 {
 		actualizarCache(pid, numeroPagina, marcoInt)
 	}
 
@@ -518,3 +524,39 @@ func leerDeMemoria(pid, direccionLogica, tamano int) string {
 	utils.InfoLog.Info(fmt.Sprintf("PID: %d - Acción: LEER - Dir Física: %d - Valor: %s", pid, direccionFisica, valor))
 	return valor
 }
+
+// adjuntarSegmento pide a Memoria crear (si no existe) y mapear un segmento compartido en el proceso
+func adjuntarSegmento(pid int, nombre string, tamano, direccionLogica int) error {
+	params := map[string]interface{}{
+		"pid":       pid,
+		"nombre":    nombre,
+		"tamanio":   tamano,
+		"direccion": direccionLogica,
+	}
+	return enviarOperacionSegmento(utils.MensajeAdjuntarSegmento, params)
+}
+
+// desadjuntarSegmento quita el segmento compartido del espacio lógico del proceso
+func desadjuntarSegmento(pid int, nombre string) error {
+	params := map[string]interface{}{
+		"pid":    pid,
+		"nombre": nombre,
+	}
+	return enviarOperacionSegmento(utils.MensajeDesadjuntarSegmento, params)
+}
+
+func enviarOperacionSegmento(tipo int, params map[string]interface{}) error {
+	respuesta, err := memoriaClient.EnviarHTTPMensaje(tipo, "default", params)
+	if err != nil {
+		return fmt.Errorf("error de comunicación con memoria: %w", err)
+	}
+
+	datos, ok := respuesta.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("respuesta de memoria en formato inválido")
+	}
+	if mensaje, hayError := datos["error"].(string); hayError {
+		return fmt.Errorf("memoria rechazó la operación: %s", mensaje)
+	}
+	return nil
+}
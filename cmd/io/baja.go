@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+const modoBajaDrenar = "DRENAR" // MODO_BAJA: termina las peticiones en curso antes de cerrarse
+
+var (
+	// peticionesEnCurso cuenta las peticiones atendiéndose y sus notificaciones al Kernel pendientes
+	peticionesEnCurso sync.WaitGroup
+	saliendo          bool
+	saliendoMutex     sync.Mutex
+)
+
+// iniciarPeticion registra una petición nueva, salvo que el dispositivo ya se esté dando de baja
+func iniciarPeticion() bool {
+	saliendoMutex.Lock()
+	defer saliendoMutex.Unlock()
+	if saliendo {
+		return false
+	}
+	peticionesEnCurso.Add(1)
+	return true
+}
+
+func terminarPeticion() {
+	peticionesEnCurso.Done()
+}
+
+// escucharSenalesDeSalida da de baja el dispositivo en el kernel al recibir SIGINT o SIGTERM. Con
+// MODO_BAJA "DRENAR" termina las peticiones que ya tenía antes de cerrarse; una segunda señal lo
+// cierra sin esperar
+func escucharSenalesDeSalida(nombreDispositivo string) {
+	senales := make(chan os.Signal, 1)
+	signal.Notify(senales, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-senales
+		drenar := config.ModoBaja == modoBajaDrenar
+		utils.InfoLog.Info("Señal de salida recibida, dando de baja el dispositivo", "dispositivo", nombreDispositivo, "drenar", drenar)
+
+		saliendoMutex.Lock()
+		saliendo = true
+		saliendoMutex.Unlock()
+
+		datos := map[string]interface{}{
+			"nombre": nombreDispositivo,
+			"tipo":   "IO" + nombreDispositivo,
+			"drenar": drenar,
+		}
+		if _, err := kernelClient.EnviarHTTPMensaje(utils.MensajeBaja, "default", datos); err != nil {
+			utils.ErrorLog.Error("No se pudo avisar la baja al Kernel", "error", err)
+		}
+
+		if drenar {
+			utils.InfoLog.Info("Esperando que terminen las peticiones en curso")
+			drenado := make(chan struct{})
+			go func() {
+				peticionesEnCurso.Wait()
+				close(drenado)
+			}()
+			select {
+			case <-drenado:
+			case <-senales:
+				utils.InfoLog.Warn("Segunda señal de salida, baja inmediata")
+			}
+		}
+
+		utils.InfoLog.Info("Dispositivo dado de baja", "dispositivo", nombreDispositivo)
+		os.Exit(0)
+	}()
+}
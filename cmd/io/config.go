@@ -2,15 +2,18 @@ package main
 
 // Estructura de configuración para IO
 type IOConfig struct {
-	IPIO        string `json:"IP_IO"`
-	PortIO      int    `json:"PUERTO_IO"`
-	IPKernel    string `json:"IP_KERNEL"`
-	PortKernel  int    `json:"PUERTO_KERNEL"`
-	LogLevel    string `json:"LOG_LEVEL"`
-	RetardoBase int    `json:"RETARDO_BASE"`
+	IPIO        string  `json:"IP_IO"`
+	PortIO      int     `json:"PUERTO_IO"`
+	IPKernel    string  `json:"IP_KERNEL"`
+	PortKernel  int     `json:"PUERTO_KERNEL"`
+	LogLevel    string  `json:"LOG_LEVEL"`
+	RetardoBase int     `json:"RETARDO_BASE"`
+	ModoReloj   string  `json:"MODO_RELOJ,omitempty"`
+	FactorReloj float64 `json:"FACTOR_RELOJ,omitempty"`
+	ModoBaja    string  `json:"MODO_BAJA,omitempty"`
 }
 
 // Variables globales
 var (
 	config *IOConfig
-)
\ No newline at end of file
+)
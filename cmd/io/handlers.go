@@ -14,6 +14,14 @@ func handlerHandshake(msg *utils.Mensaje) (interface{}, error) {
 
 // Handler para operaciones IO
 func handlerOperacion(msg *utils.Mensaje) (interface{}, error) {
+	if !iniciarPeticion() {
+		utils.InfoLog.Warn("Petición rechazada, el dispositivo se está dando de baja", "origen", msg.Origen)
+		return map[string]interface{}{
+			"status":  "ERROR",
+			"mensaje": "Dispositivo dándose de baja",
+		}, nil
+	}
+	defer terminarPeticion()
 	return utils.HandlerGenerico(msg, config.RetardoBase, procesarOperacion)
 }
 
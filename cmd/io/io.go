@@ -13,7 +13,7 @@ func notificarIOTerminadaAKernel(pid int) {
         "evento":    "IO_TERMINADA",
         "operacion": "IO_COMPLETADA",
         "pid":       pid,
-        "timestamp": time.Now().UnixNano() / int64(time.Millisecond),
+        "timestamp": utils.Ahora().UnixNano() / int64(time.Millisecond),
     }
 
     if kernelClient == nil {
@@ -71,8 +71,12 @@ func procesarOperacion(msg *utils.Mensaje) (interface{}, error) {
 	// Log de fin de IO
 	utils.InfoLog.Info(fmt.Sprintf("PID: %d - Fin de IO", pid))
 
-	// Notificar al Kernel que la operación IO ha terminado
-	go notificarIOTerminadaAKernel(pid)
+	// Notificar al Kernel que la operación IO ha terminado. Al drenar también se espera esta notificación
+	peticionesEnCurso.Add(1)
+	go func() {
+		defer peticionesEnCurso.Done()
+		notificarIOTerminadaAKernel(pid)
+	}()
 
 	return map[string]interface{}{
 		"status":  "OK",
@@ -31,6 +31,7 @@ func main() {
 
 	// Inicializar módulo
 	inicializarModulo(rutaConfig, nombreDispositivo)
+	escucharSenalesDeSalida(nombreDispositivo)
 
 	// Mantener vivo el proceso
 	select {}
@@ -50,6 +51,11 @@ func inicializarModulo(rutaConfig string, nombreDispositivo string) {
 	// Actualizar nivel de log
 	utils.InicializarLogger(config.LogLevel, loggerName)
 
+	if err := utils.ConfigurarRelojCompartido(config.ModoReloj, config.FactorReloj, config.IPKernel, config.PortKernel, config.IPIO, config.PortIO); err != nil {
+		utils.ErrorLog.Error("Configuración de reloj inválida", "error", err)
+		os.Exit(1)
+	}
+
 	utils.InfoLog.Info("Módulo IO inicializado",
 		"dispositivo", nombreDispositivo,
 		"config_path", rutaConfig,
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"sort"
 	"time"
 
 	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
@@ -12,6 +11,13 @@ const (
 	tiempoEsperaReintentos = 2 * time.Second
 )
 
+var (
+	// espacioDisponibleLTS es el espacio (bytes) con el que se evalúa la admisión; -1 si es desconocido.
+	// Protegido por newMutex, igual que liberacionesMemoria
+	espacioDisponibleLTS int = -1
+	liberacionesMemoria  int
+)
+
 // PlanificarLargoPlazo optimizado
 func PlanificarLargoPlazo() {
 	defer func() {
@@ -24,7 +30,9 @@ func PlanificarLargoPlazo() {
 	utils.InfoLog.Info("Iniciando Planificador de Largo Plazo")
 
 	for {
+		esperarSiDetenida()
 		var pcb *PCB
+		desdeSuspReady := false
 
 		// Esperar hasta que haya procesos disponibles (SUSP.READY tiene prioridad)
 		for {
@@ -35,43 +43,50 @@ func PlanificarLargoPlazo() {
 				pcb = colaSuspReady[0]
 				colaSuspReady = colaSuspReady[1:]
 				suspReadyMutex.Unlock()
-
-				semaforoMultiprogram.Wait()
-
-				// Verificar si el proceso necesita desswap
-				if pcb.EnSwap {
-					// Proceso suspendido por timeout, necesita desswap
-					go notificarDesswapAMemoria(pcb.PID)
-					utils.InfoLog.Info("Proceso de SUSP.READY enviado a desswap", "pid", pcb.PID)
-				} else {
-					// Proceso completó IO, ya está en memoria
-					pcb.CambiarEstado(EstadoReady)
-					readyMutex.Lock()
-					colaReady = append(colaReady, pcb)
-					readyMutex.Unlock()
-					condReady.Signal()
-					utils.InfoLog.Info("Proceso movido de SUSP.READY a READY (ya en memoria)", "pid", pcb.PID)
-				}
-				break // Salir del loop interno para procesar siguiente
+				desdeSuspReady = true
+				break // Salir del loop interno para procesar
 			}
 			suspReadyMutex.Unlock()
 
-			// Si no hay procesos en SUSP.READY, revisar NEW
+			// Si no hay procesos en SUSP.READY, revisar NEW según el espacio libre actual de Memoria
+			actualizarEspacioDisponibleLTS()
 			newMutex.Lock()
-			if len(colaNew) > 0 {
-				pcb = seleccionarProcesoLTS()
-				if pcb != nil {
+			liberacionesVistas := liberacionesMemoria
+			pcb = seleccionarProcesoLTS()
+			if pcb != nil {
+				if pcb.PID == 0 || entraEnMemoria(pcb) {
 					newMutex.Unlock()
 					break // Salir del loop interno para procesar
 				}
+
+				registrarIntentoAdmision(pcb, "NO_ENTRA")
+				if !hayProcesosEnMemoria() {
+					// Nadie puede liberar memoria: el proceso es más grande que la memoria disponible
+					newMutex.Unlock()
+					FinalizarProceso(pcb, "MEMORIA_INSUFICIENTE")
+					continue
+				}
 			}
 
-			// No hay procesos en ninguna cola, esperar señales
-			utils.InfoLog.Info("LTS esperando procesos disponibles")
-			condNew.Wait() // Espera señales de NEW o SUSP.READY
+			// Esperar señales de NEW, SUSP.READY o memoria liberada
+			if liberacionesMemoria == liberacionesVistas {
+				utils.InfoLog.Info("LTS esperando procesos disponibles")
+				condNew.Wait()
+			}
 			newMutex.Unlock()
 		}
 
+		// Los procesos de SUSP.READY ya pasaron por la admisión desde NEW
+		if desdeSuspReady {
+			admitirDesdeSuspReady(pcb)
+			continue
+		}
+
+		// Si se pausó la planificación mientras se esperaba, el proceso queda en NEW
+		if pcb.Estado == EstadoNew && planificacionEstaDetenida() {
+			continue
+		}
+
 		// Caso especial para proceso inicial (PID 0)
 		if pcb.PID == 0 {
 			utils.InfoLog.Info("Admitiendo proceso inicial", "pid", 0)
@@ -79,10 +94,7 @@ func PlanificarLargoPlazo() {
 
 			if inicializarEnMemoriaConReintentos(pcb) {
 				pcb.CambiarEstado(EstadoReady)
-				readyMutex.Lock()
-				colaReady = append(colaReady, pcb)
-				readyMutex.Unlock()
-				condReady.Signal()
+				agregarAReady(pcb)
 				utils.InfoLog.Info("Proceso inicial admitido a READY", "pid", pcb.PID)
 			} else {
 				utils.ErrorLog.Error("Error al inicializar proceso inicial", "pid", pcb.PID)
@@ -91,26 +103,73 @@ func PlanificarLargoPlazo() {
 			continue
 		}
 
+		// El algoritmo de corto plazo puede rechazar el proceso (ej. test de planificabilidad de tiempo real).
+		// Solo se evalúa al admitirlo desde NEW: al volver de SUSP.READY su utilización ya está contada
+		if conAdmision, ok := planificadorCortoPlazo().(planificadorConAdmision); ok && pcb.Estado == EstadoNew && !conAdmision.Admitir(pcb) {
+			utils.InfoLog.Warn("Proceso rechazado por el algoritmo de corto plazo", "pid", pcb.PID, "algoritmo", configKernel().SchedulerAlgorithm)
+			FinalizarProceso(pcb, "RECHAZADO_TEST_PLANIFICABILIDAD")
+			continue
+		}
+
+		// La presión de memoria se evalúa antes del semáforo: suspender bloqueados también libera
+		// lugar en el grado de multiprogramación, que de otro modo podría estar ocupado por ellos
+		if suspensionPorPresion() {
+			liberarMemoriaPara(pcb)
+		}
+
 		// Esperar semáforo antes de inicializar en memoria
 		semaforoMultiprogram.Wait()
 
-		if inicializarEnMemoriaConReintentos(pcb) {
-			removerDeCola(&colaNew, pcb)
+		liberacionesVistas := liberacionesDeMemoria()
+		if inicializarProcesoEnMemoria(pcb.PID, pcb.Tamanio, pcb.NombreArchivo) {
+			removerDeNew(pcb)
 			pcb.CambiarEstado(EstadoReady)
-
-			readyMutex.Lock()
-			colaReady = append(colaReady, pcb)
-			readyMutex.Unlock()
-			condReady.Signal()
-			utils.InfoLog.Info("Proceso admitido a READY", "pid", pcb.PID)
+			agregarAReady(pcb)
+			registrarIntentoAdmision(pcb, "ADMITIDO")
 		} else {
-			removerDeCola(&colaNew, pcb)
-			FinalizarProceso(pcb, "ERROR_INICIALIZACION_MEMORIA")
+			// El proceso queda en NEW hasta que Memoria libere espacio
 			semaforoMultiprogram.Signal()
+			registrarIntentoAdmision(pcb, "RECHAZADO_POR_MEMORIA")
+			esperarLiberacionMemoria(liberacionesVistas)
 		}
 	}
 }
 
+// admitirDesdeSuspReady trae a READY un proceso de SUSP.READY. Si Memoria no puede cargarlo desde SWAP,
+// vuelve al frente de SUSP.READY hasta que se libere memoria
+func admitirDesdeSuspReady(pcb *PCB) {
+	if pcb.Estado == EstadoExit {
+		return
+	}
+
+	// Igual que desde NEW, la presión de memoria se evalúa antes de esperar el semáforo
+	if suspensionPorPresion() {
+		liberarMemoriaPara(pcb)
+	}
+
+	semaforoMultiprogram.Wait()
+
+	if pcb.EnSwap {
+		// Proceso suspendido por timeout, necesita desswap
+		liberacionesVistas := liberacionesDeMemoria()
+		if !notificarDesswapAMemoria(pcb.PID) {
+			semaforoMultiprogram.Signal()
+			suspReadyMutex.Lock()
+			colaSuspReady = append([]*PCB{pcb}, colaSuspReady...)
+			suspReadyMutex.Unlock()
+			utils.ErrorLog.Error("Memoria no pudo cargar el proceso desde SWAP, vuelve a SUSP.READY", "pid", pcb.PID)
+			esperarLiberacionMemoria(liberacionesVistas)
+			return
+		}
+		pcb.EnSwap = false
+		utils.InfoLog.Info("Proceso de SUSP.READY cargado desde SWAP", "pid", pcb.PID)
+	}
+
+	pcb.CambiarEstado(EstadoReady)
+	agregarAReady(pcb)
+	utils.InfoLog.Info("Proceso movido de SUSP.READY a READY", "pid", pcb.PID)
+}
+
 // inicializarEnMemoriaConReintentos maneja reintentos automáticamente
 func inicializarEnMemoriaConReintentos(pcb *PCB) bool {
 	utils.InfoLog.Info("Inicializando proceso en memoria", "pid", pcb.PID, "max_intentos", maxIntentosMemoria)
@@ -123,7 +182,7 @@ func inicializarEnMemoriaConReintentos(pcb *PCB) bool {
 
 		if intento < maxIntentosMemoria {
 			utils.InfoLog.Warn("Intento fallido, reintentando", "pid", pcb.PID, "intento", intento, "espera", tiempoEsperaReintentos)
-			time.Sleep(tiempoEsperaReintentos)
+			utils.Dormir(tiempoEsperaReintentos)
 		}
 	}
 
@@ -131,53 +190,86 @@ func inicializarEnMemoriaConReintentos(pcb *PCB) bool {
 	return false
 }
 
-// seleccionarProcesoLTS selecciona el próximo proceso según algoritmo
+// seleccionarProcesoLTS pide al algoritmo de ingreso configurado el próximo proceso de NEW. Requiere newMutex tomado
 func seleccionarProcesoLTS() *PCB {
 	if len(colaNew) == 0 {
 		return nil
 	}
 
-	algoritmo := kernelConfig.ReadyIngressAlgorithm
-	utils.InfoLog.Info("Seleccionando proceso LTS", "algoritmo", algoritmo, "procesos_disponibles", len(colaNew))
+	utils.InfoLog.Info("Seleccionando proceso LTS", "algoritmo", configKernel().ReadyIngressAlgorithm, "procesos_disponibles", len(colaNew), "espacio_disponible", espacioDisponibleLTS)
+	return planificadorLTS.SeleccionarSiguiente()
+}
 
-	switch algoritmo {
-	case "FIFO":
-		return seleccionarFIFOLTS()
-	case "PMCP":
-		return seleccionarPMCP()
-	default:
-		utils.InfoLog.Warn("Algoritmo LTS no reconocido, usando FIFO", "algoritmo", algoritmo)
-		return seleccionarFIFOLTS()
+// actualizarEspacioDisponibleLTS consulta a Memoria el espacio que pueden usar los procesos de NEW.
+// Con presión de memoria suma lo que liberaría suspender a los procesos bloqueados
+func actualizarEspacioDisponibleLTS() {
+	espacio, ok := consultarEspacioLibre()
+	if !ok {
+		espacio = -1
+	} else if suspensionPorPresion() {
+		blockedMutex.Lock()
+		for _, bloqueado := range colaBlocked {
+			espacio += bloqueado.Tamanio
+		}
+		blockedMutex.Unlock()
 	}
+
+	newMutex.Lock()
+	espacioDisponibleLTS = espacio
+	newMutex.Unlock()
 }
 
-// seleccionarFIFOLTS implementa selección FIFO
-func seleccionarFIFOLTS() *PCB {
-	return colaNew[0]
+// entraEnMemoria indica si el proceso entra en el espacio disponible. Si no se pudo consultar, se asume que sí.
+// Requiere newMutex tomado
+func entraEnMemoria(pcb *PCB) bool {
+	return espacioDisponibleLTS < 0 || pcb.Tamanio <= espacioDisponibleLTS
 }
 
-// seleccionarPMCP implementa Programación Multiprogramada Controlada por Prioridad
-func seleccionarPMCP() *PCB {
-	if len(colaNew) == 0 {
-		return nil
+// hayProcesosEnMemoria indica si algún proceso ocupa memoria y podría liberarla al finalizar o suspenderse
+func hayProcesosEnMemoria() bool {
+	mapaMutex.RLock()
+	defer mapaMutex.RUnlock()
+
+	for _, pcb := range mapaPCBs {
+		if pcb.Estado == EstadoReady || pcb.Estado == EstadoExec || pcb.Estado == EstadoBlocked {
+			return true
+		}
 	}
+	return false
+}
 
-	// Crear copia para ordenar
-	candidatos := make([]*PCB, len(colaNew))
-	copy(candidatos, colaNew)
+// registrarIntentoAdmision deja constancia del resultado de cada intento de pasar un proceso de NEW a READY
+func registrarIntentoAdmision(pcb *PCB, resultado string) {
+	utils.InfoLog.Info("Intento de admisión",
+		"pid", pcb.PID,
+		"algoritmo", configKernel().ReadyIngressAlgorithm,
+		"tamaño", pcb.Tamanio,
+		"espacio_disponible", espacioDisponibleLTS,
+		"resultado", resultado)
+}
 
-	// Ordenar por tamaño (menor tamaño = mayor prioridad)
-	sort.Slice(candidatos, func(i, j int) bool {
-		if candidatos[i].Tamanio == candidatos[j].Tamanio {
-			return candidatos[i].HoraCreacion.Before(candidatos[j].HoraCreacion)
-		}
-		return candidatos[i].Tamanio < candidatos[j].Tamanio
-	})
+// notificarMemoriaLiberada despierta al LTS cuando un proceso finaliza o se suspende
+func notificarMemoriaLiberada() {
+	newMutex.Lock()
+	liberacionesMemoria++
+	newMutex.Unlock()
+	condNew.Broadcast()
+}
 
-	seleccionado := candidatos[0]
-	utils.InfoLog.Info("PMCP seleccionó proceso", "pid", seleccionado.PID, "tamaño", seleccionado.Tamanio)
+// liberacionesDeMemoria devuelve el contador de liberaciones de memoria
+func liberacionesDeMemoria() int {
+	newMutex.Lock()
+	defer newMutex.Unlock()
+	return liberacionesMemoria
+}
 
-	return seleccionado
+// esperarLiberacionMemoria bloquea hasta que se libere memoria después de la liberación vista
+func esperarLiberacionMemoria(liberacionesVistas int) {
+	newMutex.Lock()
+	defer newMutex.Unlock()
+	for liberacionesMemoria == liberacionesVistas {
+		condNew.Wait()
+	}
 }
 
 // inicializarProcesoEnMemoria simplificado
@@ -226,6 +318,7 @@ func notificarDesswapAMemoria(pid int) bool {
 
 	// Log para visualizar la petición a Memoria para cargar desde SWAP
 	utils.InfoLog.Info("Notificando a Memoria: Cargar desde SWAP", "pid", pid)
+	trazarEvento(EventoSwapEntrada, pid, "MEMORIA", "")
 
 	datos := map[string]interface{}{
 		"pid": pid,
@@ -1,8 +1,9 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -44,6 +45,7 @@ func registrarCPU(nombre string, ip string, puerto int) {
 	}
 
 	cpuClients[nombreCPU] = utils.NewHTTPClient(ip, puerto, "Kernel->"+nombreCPU)
+	iniciarMonitorCPU(nombreCPU, ip, puerto)
 
 	utils.InfoLog.Info("CPU registrada correctamente", "nombre", nombreCPU, "ip", ip, "puerto", puerto, "total_cpus", len(cpuClients))
 }
@@ -60,23 +62,29 @@ func PlanificarCortoPlazo() {
 	utils.InfoLog.Info("Iniciando Planificador de Corto Plazo")
 
 	for {
+		esperarSiDetenida()
 		utils.InfoLog.Info("Esperando procesos en READY")
 		readyMutex.Lock()
-		for len(colaReady) == 0 {
+		for !hayProcesosListos() {
 			condReady.Wait()
 		}
-		utils.InfoLog.Info("Proceso detectado en READY", "procesos_en_ready", len(colaReady))
+		utils.InfoLog.Info("Proceso detectado en READY", "procesos_en_ready", cantidadProcesosListos())
+
+		if planificacionEstaDetenida() {
+			readyMutex.Unlock()
+			continue
+		}
 
 		pcb := seleccionarProcesoSTS()
 
 		if pcb != nil {
 			utils.InfoLog.Info("Proceso seleccionado", "pid", pcb.PID)
-			removerDeCola(&colaReady, pcb)
+			quitarDeListos(pcb)
 		}
 
 		if pcb == nil {
 			readyMutex.Unlock()
-			time.Sleep(100 * time.Millisecond)
+			utils.Dormir(100 * time.Millisecond)
 			continue
 		}
 
@@ -94,13 +102,18 @@ func PlanificarCortoPlazo() {
 				colaExec[nombreCPU] = pcb
 				execMutex.Unlock()
 				utils.InfoLog.Info("CPU encontrada y reservada", "nombre", nombreCPU)
+				trazarEvento(EventoDespacho, pcb.PID, nombreCPU, "")
 				break
 			}
 			utils.InfoLog.Warn("No hay CPU disponible, reintentando")
-			time.Sleep(200 * time.Millisecond)
+			utils.Dormir(200 * time.Millisecond)
 		}
 
+		pcb.CPUAsignada = nombreCPU
 		pcb.CambiarEstado(EstadoExec)
+		if conQuantum, ok := planificadorCortoPlazo().(planificadorConQuantum); ok {
+			iniciarTimerQuantum(pcb, conQuantum.QuantumPara(pcb))
+		}
 		utils.InfoLog.Info("Proceso despachado a CPU", "pid", pcb.PID, "cpu", nombreCPU)
 
 		go despacharYProcesarCPU(nombreCPU, cpuClient, pcb)
@@ -113,11 +126,22 @@ func despacharYProcesarCPU(nombreCPU string, cpuClient *utils.HTTPClient, pcb *P
 
 	defer func() {
 		utils.InfoLog.Info("Liberando CPU", "pid", pcb.PID, "cpu", nombreCPU)
+		detenerTimerQuantum(pcb)
 		execMutex.Lock()
-		delete(colaExec, nombreCPU)
+		// Solo liberar si la CPU no fue reasignada a otro proceso mientras tanto
+		if colaExec[nombreCPU] == pcb {
+			delete(colaExec, nombreCPU)
+		}
 		execMutex.Unlock()
+		completarDrenadoCPU(nombreCPU)
 	}()
 
+	// Lo recibido por IPC mientras estaba bloqueado se escribe ahora que su memoria está cargada
+	if !completarEntregaIPC(pcb) {
+		FinalizarProceso(pcb, "ERROR_IPC")
+		return
+	}
+
 	// Ciclo de ejecución en CPU
 	for {
 		// VERIFICACIÓN CRÍTICA: Comprobar si el proceso sigue existiendo
@@ -168,11 +192,13 @@ func despacharYProcesarCPU(nombreCPU string, cpuClient *utils.HTTPClient, pcb *P
 
 // obtenerCPUDisponibleParaEjecucion busca CPU que no esté ejecutando
 func obtenerCPUDisponibleParaEjecucion() (string, *utils.HTTPClient) {
-	// Obtener CPUs registradas
+	// Obtener CPUs registradas que responden los latidos y no están drenando
 	cpuClientsMutex.Lock()
 	cpusDisponibles := make(map[string]*utils.HTTPClient)
 	for nombre, cliente := range cpuClients {
-		cpusDisponibles[nombre] = cliente
+		if cpuAceptaProcesos(nombre) {
+			cpusDisponibles[nombre] = cliente
+		}
 	}
 	cpuClientsMutex.Unlock()
 
@@ -199,115 +225,53 @@ func obtenerCPUDisponibleParaEjecucion() (string, *utils.HTTPClient) {
 	return "", nil
 }
 
-// seleccionarProcesoSTS selecciona proceso según algoritmo configurado
+// seleccionarProcesoSTS pide al algoritmo configurado el próximo proceso y, si no hay CPU libre,
+// le consulta si corresponde desalojar a alguno de los procesos en ejecución. Requiere readyMutex tomado
 func seleccionarProcesoSTS() *PCB {
-	if len(colaReady) == 0 {
+	if !hayProcesosListos() {
 		return nil
 	}
 
-	algoritmo := kernelConfig.SchedulerAlgorithm
-	utils.InfoLog.Info("Seleccionando proceso STS", "algoritmo", algoritmo, "procesos_disponibles", len(colaReady))
-
-	switch algoritmo {
-	case "FIFO":
-		return seleccionarFIFO()
-	case "SJF":
-		return seleccionarSJF()
-	case "SRT":
-		return seleccionarSRT()
-	default:
-		utils.InfoLog.Warn("Algoritmo STS no reconocido, usando FIFO", "algoritmo", algoritmo)
-		return seleccionarFIFO()
-	}
-}
-
-// seleccionarFIFO implementa selección FIFO
-func seleccionarFIFO() *PCB {
-	if len(colaReady) == 0 {
-		return nil
-	}
-	return colaReady[0]
-}
-
-// seleccionarSJF implementa Shortest Job First
-func seleccionarSJF() *PCB {
-	if len(colaReady) == 0 {
-		return nil
-	}
-
-	candidatos := make([]*PCB, len(colaReady))
-	copy(candidatos, colaReady)
-
-	sort.Slice(candidatos, func(i, j int) bool {
-		if candidatos[i].EstimacionSiguienteRafaga == candidatos[j].EstimacionSiguienteRafaga {
-			return candidatos[i].HoraListo.Before(candidatos[j].HoraListo)
-		}
-		return candidatos[i].EstimacionSiguienteRafaga < candidatos[j].EstimacionSiguienteRafaga
-	})
-
-	seleccionado := candidatos[0]
-	utils.InfoLog.Info("SJF seleccionó proceso", "pid", seleccionado.PID, "estimacion", seleccionado.EstimacionSiguienteRafaga)
-
-	return seleccionado
-}
+	utils.InfoLog.Info("Seleccionando proceso STS", "algoritmo", configKernel().SchedulerAlgorithm, "procesos_disponibles", cantidadProcesosListos())
 
-// seleccionarSRT implementa Shortest Remaining Time
-func seleccionarSRT() *PCB {
-	if len(colaReady) == 0 {
-		return nil
+	planificador := planificadorCortoPlazo()
+	candidato := planificador.SeleccionarSiguiente()
+	if candidato == nil || hayCPULibre() {
+		return candidato
 	}
 
-	mejorCandidatoReady := encontrarMejorCandidatoReady()
-
 	execMutex.Lock()
-	procesoADesalojar := encontrarProcesoADesalojar(mejorCandidatoReady)
+	enEjecucion := make([]*PCB, 0, len(colaExec))
+	for _, pcbEnExec := range colaExec {
+		enEjecucion = append(enEjecucion, pcbEnExec)
+	}
 	execMutex.Unlock()
 
-	if procesoADesalojar != nil {
-		utils.InfoLog.Info(fmt.Sprintf("(%d) - Desalojado por algoritmo SJF/SRT", procesoADesalojar.PID))
-		utils.InfoLog.Info("Desalojando proceso por SRT", "desalojado", procesoADesalojar.PID, "nuevo", mejorCandidatoReady.PID)
+	if procesoADesalojar := planificador.DebeDesalojar(candidato, enEjecucion); procesoADesalojar != nil {
+		utils.InfoLog.Info("Desalojando proceso", "algoritmo", configKernel().SchedulerAlgorithm, "desalojado", procesoADesalojar.PID, "nuevo", candidato.PID)
 		go desalojarProcesoActual(procesoADesalojar)
 		return nil
 	}
 
-	return mejorCandidatoReady
+	return candidato
 }
 
-func encontrarMejorCandidatoReady() *PCB {
-	if len(colaReady) == 0 {
-		return nil
-	}
-
-	mejorProceso := colaReady[0]
-	for _, pcb := range colaReady[1:] {
-		if pcb.EstimacionSiguienteRafaga < mejorProceso.EstimacionSiguienteRafaga {
-			mejorProceso = pcb
-		} else if pcb.EstimacionSiguienteRafaga == mejorProceso.EstimacionSiguienteRafaga {
-			if pcb.HoraListo.Before(mejorProceso.HoraListo) {
-				mejorProceso = pcb
-			}
-		}
-	}
-	return mejorProceso
-}
-
-func encontrarProcesoADesalojar(candidato *PCB) *PCB {
-	var procesoMasLargo *PCB = nil
-	if candidato == nil {
-		return nil
-	}
+// hayCPULibre indica si alguna CPU registrada no está ejecutando procesos
+func hayCPULibre() bool {
+	cpuClientsMutex.Lock()
+	defer cpuClientsMutex.Unlock()
+	execMutex.Lock()
+	defer execMutex.Unlock()
 
-	for _, pcbEnExec := range colaExec {
-		if candidato.EstimacionSiguienteRafaga < pcbEnExec.EstimacionSiguienteRafaga {
-			if procesoMasLargo == nil || pcbEnExec.EstimacionSiguienteRafaga > procesoMasLargo.EstimacionSiguienteRafaga {
-				procesoMasLargo = pcbEnExec
-			}
+	for nombre := range cpuClients {
+		if _, ocupada := colaExec[nombre]; !ocupada && cpuAceptaProcesos(nombre) {
+			return true
 		}
 	}
-	return procesoMasLargo
+	return false
 }
 
-// desalojarProcesoActual maneja el desalojo de un proceso por SRT
+// desalojarProcesoActual envía la interrupción a la CPU que ejecuta el proceso
 func desalojarProcesoActual(pcb *PCB) {
 	var cpuADesalojar string
 	execMutex.Lock()
@@ -334,7 +298,16 @@ func desalojarProcesoActual(pcb *PCB) {
 	}
 
 	utils.InfoLog.Info("Enviando interrupción a CPU", "cpu", cpuADesalojar, "pid", pcb.PID)
-	_, err := cpuClient.EnviarHTTPOperacion("INTERRUPT", nil)
+	motivo := "ALGORITMO"
+	if pcb.QuantumAgotado {
+		motivo = "FIN_QUANTUM"
+	}
+	trazarEvento(EventoDesalojo, pcb.PID, cpuADesalojar, motivo)
+	datos := map[string]interface{}{
+		"pid": pcb.PIDMemoria(),
+		"tid": pcb.TID,
+	}
+	_, err := cpuClient.EnviarHTTPMensaje(utils.MensajeInterrupcion, "INTERRUPCION", datos)
 	if err != nil {
 		utils.ErrorLog.Error("Fallo al enviar interrupción a CPU", "cpu", cpuADesalojar, "error", err)
 	}
@@ -347,21 +320,40 @@ func EnviarProcesoCPU(pcb *PCB, nombreCPU string) bool {
 	cpuClientsMutex.Unlock()
 
 	if !existe {
-		utils.ErrorLog.Error("CPU no encontrada", "cpu_id", nombreCPU)
+		// La CPU se dio de baja entre instrucciones: el proceso sigue desde su PC en otra CPU
+		utils.InfoLog.Warn("CPU dada de baja, el proceso vuelve a READY", "pid", pcb.PID, "cpu", nombreCPU, "pc", pcb.PC)
+		MoverProcesoAReady(pcb)
 		return false
 	}
 
+	// La CPU y Memoria conocen al hilo por el PID del proceso y su TID
 	datos := map[string]interface{}{
-		"pid": pcb.PID,
+		"pid": pcb.PIDMemoria(),
+		"tid": pcb.TID,
 		"pc":  pcb.PC,
 	}
 
 	utils.InfoLog.Info("Enviando proceso a CPU", "pid", pcb.PID, "pc", pcb.PC, "cpu", nombreCPU)
 
-	respuesta, err := cpuClient.EnviarHTTPOperacion("EJECUTAR_PROCESO", datos)
+	respuesta, err := ejecutarEnCPU(nombreCPU, cpuClient, datos)
+
+	// Si lo mataron mientras la CPU ejecutaba, la respuesta ya no aplica: no vuelve a READY ni a una cola de IO
+	if procesoFinalizado(pcb) {
+		utils.InfoLog.Info("Respuesta de CPU descartada, el proceso ya finalizó", "pid", pcb.PID, "cpu", nombreCPU)
+		return true
+	}
 
 	if err != nil {
-		utils.ErrorLog.Error("Error enviando proceso a CPU", "pid", pcb.PID, "error", err.Error())
+		var caida *caidaCPU
+		if errors.As(err, &caida) {
+			if caida.conPC {
+				pcb.PC = caida.pc // La instrucción despachada ya se ejecutó
+			}
+			utils.InfoLog.Warn("CPU caída durante la ejecución, el proceso vuelve a READY", "pid", pcb.PID, "cpu", nombreCPU, "pc", pcb.PC)
+		} else {
+			utils.ErrorLog.Error("Error enviando proceso a CPU", "pid", pcb.PID, "error", err.Error())
+			registrarFalloCPU(nombreCPU)
+		}
 		MoverProcesoAReady(pcb)
 		return false
 	}
@@ -390,12 +382,23 @@ func EnviarProcesoCPU(pcb *PCB, nombreCPU string) bool {
 				if parametros, ok := respuestaMap["parametros"].(map[string]interface{}); ok {
 					archivo, _ := parametros["archivo"].(string)
 					tamano, _ := parametros["tamano"].(float64)
+					prioridad := -1
+					if p, hayPrioridad := parametros["prioridad"].(float64); hayPrioridad {
+						prioridad = int(p)
+					}
 
-					utils.InfoLog.Info("Procesando INIT_PROC", "pid", pcb.PID, "archivo", archivo, "tamaño", int(tamano))
+					utils.InfoLog.Info("Procesando INIT_PROC", "pid", pcb.PID, "archivo", archivo, "tamaño", int(tamano), "prioridad", prioridad)
 
 					nuevoPCB := NuevoPCB(-1, int(tamano))
 					nuevoPCB.NombreArchivo = archivo
-					utils.InfoLog.Info("Nuevo proceso creado", "nuevo_pid", nuevoPCB.PID, "estado", "NEW")
+					nuevoPCB.AsignarPrioridad(prioridad)
+					nuevoPCB.Tickets = pcb.Tickets
+					if opciones, hayOpciones := parametros["opciones"].(map[string]interface{}); hayOpciones {
+						asignarTickets(nuevoPCB, opciones)
+						asignarOpcionesTiempoReal(nuevoPCB, opciones)
+					}
+					registrarHijo(pcb.procesoPrincipal(), nuevoPCB)
+					utils.InfoLog.Info("Nuevo proceso creado", "nuevo_pid", nuevoPCB.PID, "padre", pcb.PID, "estado", "NEW")
 					AgregarProcesoANew(nuevoPCB)
 				}
 
@@ -414,7 +417,126 @@ func EnviarProcesoCPU(pcb *PCB, nombreCPU string) bool {
 
 					dispositivoReal := SeleccionarDispositivoIO(dispositivo, pcb.PID)
 					MoverProcesoABlocked(pcb, fmt.Sprintf("IO_%s", dispositivoReal))
-					go EnviarSolicitudIO(pcb, dispositivoReal, int(tiempo))
+					go encolarIO(pcb, dispositivoReal, int(tiempo))
+				}
+				return true
+
+			case "SYSCALL_WAIT":
+				utils.InfoLog.Info(fmt.Sprintf("(%d) - Solicitó syscall: WAIT", pcb.PID))
+				objetivo := esperaCualquierHijo
+				recurso := ""
+				if parametros, ok := respuestaMap["parametros"].(map[string]interface{}); ok {
+					if pidHijo, hayPID := parametros["pid"].(float64); hayPID {
+						objetivo = int(pidHijo)
+					}
+					recurso, _ = parametros["recurso"].(string)
+				}
+
+				// El PC avanza antes de bloquear: al despertar, el proceso sigue después del WAIT
+				pcb.PC++
+				if recurso != "" {
+					esperarRecurso(pcb, recurso)
+				} else {
+					esperarHijo(pcb, objetivo)
+				}
+				return true
+
+			case "SYSCALL_SIGNAL":
+				utils.InfoLog.Info(fmt.Sprintf("(%d) - Solicitó syscall: SIGNAL", pcb.PID))
+				recurso := ""
+				if parametros, ok := respuestaMap["parametros"].(map[string]interface{}); ok {
+					recurso, _ = parametros["recurso"].(string)
+				}
+
+				pcb.PC++
+				liberarRecurso(pcb, recurso)
+				return true
+
+			case "SYSCALL_CLAIM":
+				utils.InfoLog.Info(fmt.Sprintf("(%d) - Solicitó syscall: CLAIM", pcb.PID))
+				recurso := ""
+				cantidad := 0.0
+				if parametros, ok := respuestaMap["parametros"].(map[string]interface{}); ok {
+					recurso, _ = parametros["recurso"].(string)
+					cantidad, _ = parametros["cantidad"].(float64)
+				}
+
+				if err := declararReclamo(pcb, recurso, int(cantidad)); err != nil {
+					utils.ErrorLog.Error("CLAIM inválido", "pid", pcb.PID, "error", err)
+					FinalizarProceso(pcb, "ERROR_RECLAMO_INVALIDO")
+					return true
+				}
+				pcb.PC++
+				return true
+
+			case "SYSCALL_THREAD_CREATE":
+				utils.InfoLog.Info(fmt.Sprintf("(%d) - Solicitó syscall: THREAD_CREATE", pcb.PID))
+				archivo := ""
+				prioridad := -1
+				if parametros, ok := respuestaMap["parametros"].(map[string]interface{}); ok {
+					archivo, _ = parametros["archivo"].(string)
+					if p, hayPrioridad := parametros["prioridad"].(float64); hayPrioridad {
+						prioridad = int(p)
+					}
+				}
+
+				pcb.PC++
+				if hilo, err := crearHilo(pcb, archivo, prioridad); err != nil {
+					utils.ErrorLog.Error("THREAD_CREATE falló", "pid", pcb.PID, "archivo", archivo, "error", err)
+				} else {
+					utils.InfoLog.Info("Nuevo hilo creado", "pid_hilo", hilo.PID, "proceso", hilo.PIDMemoria(), "tid", hilo.TID, "creador", pcb.PID)
+				}
+				return true
+
+			case "SYSCALL_THREAD_JOIN":
+				utils.InfoLog.Info(fmt.Sprintf("(%d) - Solicitó syscall: THREAD_JOIN", pcb.PID))
+				tid := 0.0
+				if parametros, ok := respuestaMap["parametros"].(map[string]interface{}); ok {
+					tid, _ = parametros["tid"].(float64)
+				}
+
+				pcb.PC++
+				esperarHilo(pcb, int(tid))
+				return true
+
+			case "SYSCALL_THREAD_EXIT":
+				utils.InfoLog.Info(fmt.Sprintf("(%d) - Solicitó syscall: THREAD_EXIT", pcb.PID))
+				// THREAD_EXIT del hilo principal termina el proceso (y con él a sus hilos), como un EXIT
+				motivo := "THREAD_EXIT"
+				if !pcb.esHiloSecundario() {
+					motivo = "EXIT"
+				}
+				FinalizarProceso(pcb, motivo)
+				return true
+
+			case "SYSCALL_IPC_CREATE", "SYSCALL_IPC_OPEN", "SYSCALL_IPC_CLOSE", "SYSCALL_IPC_SEND", "SYSCALL_IPC_RECEIVE":
+				operacion := strings.TrimPrefix(motivoRetorno, "SYSCALL_")
+				utils.InfoLog.Info(fmt.Sprintf("(%d) - Solicitó syscall: %s", pcb.PID, operacion))
+				parametros, _ := respuestaMap["parametros"].(map[string]interface{})
+				nombre, _ := parametros["nombre"].(string)
+				tipo, _ := parametros["tipo"].(string)
+				capacidad, _ := parametros["capacidad"].(float64)
+				direccion, _ := parametros["direccion"].(float64)
+				tamanio, _ := parametros["tamanio"].(float64)
+
+				// El PC avanza antes de bloquear: al despertar, el proceso sigue después de la operación
+				pcb.PC++
+				var err error
+				switch operacion {
+				case "IPC_CREATE":
+					err = crearIPC(pcb, nombre, tipo, int(capacidad))
+				case "IPC_OPEN":
+					err = abrirIPC(pcb, nombre)
+				case "IPC_CLOSE":
+					err = cerrarIPC(pcb, nombre)
+				case "IPC_SEND":
+					err = enviarIPC(pcb, nombre, int(direccion), int(tamanio))
+				case "IPC_RECEIVE":
+					err = recibirIPC(pcb, nombre, int(direccion), int(tamanio))
+				}
+				if err != nil {
+					utils.ErrorLog.Error("Operación IPC inválida", "pid", pcb.PID, "operacion", operacion, "nombre", nombre, "error", err)
+					FinalizarProceso(pcb, "ERROR_IPC")
 				}
 				return true
 
@@ -429,12 +551,22 @@ func EnviarProcesoCPU(pcb *PCB, nombreCPU string) bool {
 				utils.InfoLog.Info(fmt.Sprintf("(%d) - Solicitó syscall: EXIT", pcb.PID))
 				utils.InfoLog.Info("Proceso solicita EXIT", "pid", pcb.PID)
 				FinalizarProceso(pcb, "EXIT")
+				// EXIT desde un hilo secundario termina todo el proceso
+				if pcb.esHiloSecundario() {
+					matarProceso(pcb.procesoPrincipal(), "EXIT")
+				}
 				return true
 
 			case "ERROR":
 				utils.ErrorLog.Error("Error en ejecución de proceso", "pid", pcb.PID)
 				FinalizarProceso(pcb, "ERROR")
 				return true
+
+			case "INTERRUPTED":
+				utils.InfoLog.Info("Proceso desalojado de CPU por interrupción", "pid", pcb.PID, "cpu", nombreCPU)
+				detenerTimerQuantum(pcb)
+				MoverProcesoAReady(pcb)
+				return true
 			}
 		}
 
@@ -0,0 +1,431 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+// procesoAdmin es la vista de un PCB que expone la API de administración
+type procesoAdmin struct {
+	PID               int     `json:"pid"`
+	Estado            string  `json:"estado"`
+	Archivo           string  `json:"archivo"`
+	Tamanio           int     `json:"tamanio"`
+	PC                int     `json:"pc"`
+	Estimacion        float64 `json:"estimacion_ms"`
+	UltimaRafaga      float64 `json:"ultima_rafaga_ms"`
+	Ejecuciones       int     `json:"ejecuciones"`
+	TiempoEjecucion   float64 `json:"tiempo_ejecucion_ms"`
+	Prioridad         int     `json:"prioridad"`
+	NivelMLFQ         int     `json:"nivel_mlfq"`
+	Tickets           int     `json:"tickets"`
+	MotivoBloqueo     string  `json:"motivo_bloqueo,omitempty"`
+	EnSwap            bool    `json:"en_swap"`
+	CPU               string  `json:"cpu,omitempty"`
+	HoraCreacion      string  `json:"hora_creacion"`
+	HoraListo         string  `json:"hora_listo,omitempty"`
+	HoraEjecucion     string  `json:"hora_ejecucion,omitempty"`
+	HoraBloqueo       string  `json:"hora_bloqueo,omitempty"`
+	TiempoReal        bool    `json:"tiempo_real"`
+	DeadlinesPerdidos int     `json:"deadlines_perdidos,omitempty"`
+	QuantumRestante   float64 `json:"quantum_restante_ms,omitempty"`
+	QuantumAsignado   float64 `json:"quantum_asignado_ms,omitempty"`
+
+	ContadorEstados map[string]int     `json:"contador_estados"`
+	TiempoEstados   map[string]float64 `json:"tiempo_estados_ms"`
+	TiempoRespuesta float64            `json:"tiempo_respuesta_ms"`
+	TiempoRetorno   float64            `json:"tiempo_retorno_ms"`
+	TiempoEspera    float64            `json:"tiempo_espera_ms"`
+
+	PadrePID      int        `json:"padre_pid"`
+	Hijos         []int      `json:"hijos"`
+	EsperandoHijo string     `json:"esperando_hijo,omitempty"`
+	UltimoWait    *waitAdmin `json:"ultimo_wait,omitempty"`
+
+	Proceso       int   `json:"proceso"` // PID del hilo principal (el mismo PID si es el hilo principal)
+	TID           int   `json:"tid"`
+	Hilos         []int `json:"hilos"`
+	EsperandoHilo *int  `json:"esperando_hilo,omitempty"`
+}
+
+// waitAdmin es el hijo que entregó el último WAIT del proceso (pid -1 si no tenía hijos que esperar)
+type waitAdmin struct {
+	PID    int    `json:"pid"`
+	Estado int    `json:"estado"`
+	Motivo string `json:"motivo,omitempty"`
+}
+
+// registrarRutasAdmin registra los endpoints HTTP de administración del kernel
+func registrarRutasAdmin() {
+	kernelModulo.RegistrarRuta("GET /admin/procesos", adminListarProcesos)
+	kernelModulo.RegistrarRuta("POST /admin/procesos", adminCrearProceso)
+	kernelModulo.RegistrarRuta("GET /admin/procesos/{pid}", adminObtenerProceso)
+	kernelModulo.RegistrarRuta("DELETE /admin/procesos/{pid}", adminFinalizarProceso)
+	kernelModulo.RegistrarRuta("POST /admin/procesos/{pid}/suspender", adminSuspenderProceso)
+	kernelModulo.RegistrarRuta("POST /admin/procesos/{pid}/reanudar", adminReanudarProceso)
+	kernelModulo.RegistrarRuta("GET /admin/colas", adminListarColas)
+	kernelModulo.RegistrarRuta("GET /admin/cpus", adminListarCPUs)
+	kernelModulo.RegistrarRuta("GET /admin/io", adminListarIO)
+	kernelModulo.RegistrarRuta("GET /admin/recursos", adminListarRecursos)
+	kernelModulo.RegistrarRuta("POST /admin/recursos/{nombre}", adminCrearRecurso)
+	kernelModulo.RegistrarRuta("GET /admin/deadlock", adminObtenerDeadlock)
+	kernelModulo.RegistrarRuta("GET /admin/ipc", adminListarIPC)
+}
+
+func adminListarProcesos(w http.ResponseWriter, r *http.Request) {
+	mapaMutex.RLock()
+	procesos := make([]*PCB, 0, len(mapaPCBs))
+	for _, pcb := range mapaPCBs {
+		procesos = append(procesos, pcb)
+	}
+	mapaMutex.RUnlock()
+	sort.Slice(procesos, func(i, j int) bool { return procesos[i].PID < procesos[j].PID })
+
+	cpus := cpuPorPID()
+	vistas := make([]procesoAdmin, len(procesos))
+	for i, pcb := range procesos {
+		vistas[i] = vistaProceso(pcb, cpus[pcb.PID])
+	}
+	utils.ResponderJSON(w, http.StatusOK, vistas)
+}
+
+// adminCrearProceso crea un proceso en NEW. Además de archivo y tamanio acepta prioridad y las mismas
+// opciones que CREAR (tickets, periodo, deadline, wcet)
+func adminCrearProceso(w http.ResponseWriter, r *http.Request) {
+	consulta := r.URL.Query()
+	archivo := consulta.Get("archivo")
+	if archivo == "" {
+		responderErrorAdmin(w, http.StatusBadRequest, "falta el archivo de pseudocódigo")
+		return
+	}
+	tamanio, err := strconv.Atoi(consulta.Get("tamanio"))
+	if err != nil || tamanio < 0 {
+		responderErrorAdmin(w, http.StatusBadRequest, fmt.Sprintf("tamaño inválido: %s", consulta.Get("tamanio")))
+		return
+	}
+
+	argumentos := []string{}
+	for clave, valores := range consulta {
+		switch clave {
+		case "archivo", "tamanio":
+		case "prioridad":
+			argumentos = append(argumentos, valores[0])
+		default:
+			argumentos = append(argumentos, clave+"="+valores[0])
+		}
+	}
+	prioridad, opciones, err := parsearParametrosProceso(argumentos)
+	if err != nil {
+		responderErrorAdmin(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pcb := NuevoPCB(-1, tamanio)
+	pcb.NombreArchivo = archivo
+	pcb.AsignarPrioridad(prioridad)
+	asignarTickets(pcb, opciones)
+	asignarOpcionesTiempoReal(pcb, opciones)
+	utils.InfoLog.Info("Proceso creado por administración", "pid", pcb.PID, "archivo", archivo, "tamaño", tamanio, "opciones", opciones)
+	AgregarProcesoANew(pcb)
+
+	utils.ResponderJSON(w, http.StatusCreated, vistaProceso(pcb, ""))
+}
+
+func adminObtenerProceso(w http.ResponseWriter, r *http.Request) {
+	pcb, ok := procesoDeRuta(w, r)
+	if !ok {
+		return
+	}
+	utils.ResponderJSON(w, http.StatusOK, vistaProceso(pcb, cpuPorPID()[pcb.PID]))
+}
+
+func adminFinalizarProceso(w http.ResponseWriter, r *http.Request) {
+	pcb, ok := procesoDeRuta(w, r)
+	if !ok {
+		return
+	}
+
+	// ?arbol=true finaliza también todos los descendientes
+	if arbol, _ := strconv.ParseBool(r.URL.Query().Get("arbol")); arbol {
+		utils.InfoLog.Info("Finalización de subárbol solicitada por administración", "pid", pcb.PID, "estado", pcb.Estado)
+		finalizados := matarArbolProcesos(pcb, "FINALIZADO_POR_ADMINISTRACION")
+		utils.ResponderJSON(w, http.StatusOK, map[string]interface{}{"status": "OK", "pid": pcb.PID, "finalizados": finalizados})
+		return
+	}
+
+	utils.InfoLog.Info("Finalización solicitada por administración", "pid", pcb.PID, "estado", pcb.Estado)
+	matarProceso(pcb, "FINALIZADO_POR_ADMINISTRACION")
+	utils.ResponderJSON(w, http.StatusOK, map[string]interface{}{"status": "OK", "pid": pcb.PID})
+}
+
+func adminSuspenderProceso(w http.ResponseWriter, r *http.Request) {
+	pcb, ok := procesoDeRuta(w, r)
+	if !ok {
+		return
+	}
+
+	if pcb.Estado != EstadoBlocked {
+		responderErrorAdmin(w, http.StatusConflict, fmt.Sprintf("solo se pueden suspender procesos en %s (estado actual: %s)", EstadoBlocked, pcb.Estado))
+		return
+	}
+
+	utils.InfoLog.Info("Suspensión solicitada por administración", "pid", pcb.PID)
+	cancelarTimerSuspension(pcb.PID)
+	if !suspenderProceso(pcb.PID) {
+		responderErrorAdmin(w, http.StatusConflict, fmt.Sprintf("no se pudo suspender el proceso %d", pcb.PID))
+		return
+	}
+	utils.ResponderJSON(w, http.StatusOK, map[string]interface{}{"status": "OK", "pid": pcb.PID, "estado": pcb.Estado})
+}
+
+func adminReanudarProceso(w http.ResponseWriter, r *http.Request) {
+	pcb, ok := procesoDeRuta(w, r)
+	if !ok {
+		return
+	}
+
+	utils.InfoLog.Info("Reanudación solicitada por administración", "pid", pcb.PID)
+	if err := reanudarProcesoSuspendido(pcb); err != nil {
+		responderErrorAdmin(w, http.StatusConflict, err.Error())
+		return
+	}
+	utils.ResponderJSON(w, http.StatusOK, map[string]interface{}{"status": "OK", "pid": pcb.PID, "estado": pcb.Estado})
+}
+
+func adminListarColas(w http.ResponseWriter, r *http.Request) {
+	colas := map[string]interface{}{}
+
+	newMutex.Lock()
+	colas["NEW"] = pidsDeCola(colaNew)
+	newMutex.Unlock()
+
+	readyMutex.Lock()
+	colas["READY"] = pidsDeCola(colaReady)
+	colas["READY_PRIORITARIA"] = pidsDeCola(colaReadyPrioritaria)
+	nivelesMLFQ := make([][]int, len(colasMLFQ))
+	for nivel, cola := range colasMLFQ {
+		nivelesMLFQ[nivel] = pidsDeCola(cola)
+	}
+	colas["READY_MLFQ"] = nivelesMLFQ
+	readyMutex.Unlock()
+
+	colas["EXEC"] = pidPorCPU()
+
+	blockedMutex.Lock()
+	colas["BLOCKED"] = pidsDeCola(colaBlocked)
+	blockedMutex.Unlock()
+
+	suspReadyMutex.Lock()
+	colas["SUSP_READY"] = pidsDeCola(colaSuspReady)
+	suspReadyMutex.Unlock()
+
+	suspBlockedMutex.Lock()
+	colas["SUSP_BLOCKED"] = pidsDeCola(colaSuspBlocked)
+	suspBlockedMutex.Unlock()
+
+	exitMutex.Lock()
+	colas["EXIT"] = pidsDeCola(colaExit)
+	exitMutex.Unlock()
+
+	enUso, capacidad := semaforoMultiprogram.Estado()
+	colas["multiprogramacion"] = map[string]int{"en_uso": enUso, "grado": capacidad}
+	colas["planificacion_detenida"] = planificacionEstaDetenida()
+
+	utils.ResponderJSON(w, http.StatusOK, colas)
+}
+
+func adminListarCPUs(w http.ResponseWriter, r *http.Request) {
+	ejecutando := pidPorCPU()
+
+	cpuClientsMutex.Lock()
+	cpus := make([]map[string]interface{}, 0, len(cpuClients))
+	for nombre, cliente := range cpuClients {
+		cpu := map[string]interface{}{"nombre": nombre, "direccion": cliente.BaseURL, "libre": true, "latidos_perdidos": latidosPerdidosCPU(nombre), "drenando": cpusDrenando[nombre]}
+		if pid, ocupada := ejecutando[nombre]; ocupada {
+			cpu["libre"] = false
+			cpu["pid"] = pid
+		}
+		cpus = append(cpus, cpu)
+	}
+	cpuClientsMutex.Unlock()
+	sort.Slice(cpus, func(i, j int) bool { return cpus[i]["nombre"].(string) < cpus[j]["nombre"].(string) })
+
+	utils.ResponderJSON(w, http.StatusOK, cpus)
+}
+
+func adminListarIO(w http.ResponseWriter, r *http.Request) {
+	ioEnCursoMutex.Lock()
+	atendiendo := make(map[string][]int, len(ioEnCurso))
+	for nombre, pids := range ioEnCurso {
+		atendiendo[nombre] = append([]int{}, pids...)
+	}
+	ioEnCursoMutex.Unlock()
+	esperando := esperandoIO()
+
+	dispositivosIOMutex.RLock()
+	dispositivos := make([]map[string]interface{}, 0, len(dispositivosIO))
+	for nombre, cliente := range dispositivosIO {
+		pids := atendiendo[nombre]
+		if pids == nil {
+			pids = []int{}
+		}
+		instancia := nombre
+		if esAliasIO(nombre) {
+			instancia = strings.TrimPrefix(nombre, "IO")
+		}
+		cola := esperando[instancia]
+		if cola == nil {
+			cola = []int{}
+		}
+		dispositivos = append(dispositivos, map[string]interface{}{
+			"nombre":        nombre,
+			"direccion":     cliente.BaseURL,
+			"atendiendo":    pids,
+			"en_espera":     cola,
+			"largo_de_cola": len(cola),
+		})
+	}
+	dispositivosIOMutex.RUnlock()
+	sort.Slice(dispositivos, func(i, j int) bool {
+		return dispositivos[i]["nombre"].(string) < dispositivos[j]["nombre"].(string)
+	})
+
+	utils.ResponderJSON(w, http.StatusOK, dispositivos)
+}
+
+func adminListarRecursos(w http.ResponseWriter, r *http.Request) {
+	utils.ResponderJSON(w, http.StatusOK, map[string]interface{}{"orden": ordenRecursos(), "recursos": estadoRecursos()})
+}
+
+func adminCrearRecurso(w http.ResponseWriter, r *http.Request) {
+	instancias := 1
+	if valor := r.URL.Query().Get("instancias"); valor != "" {
+		cantidad, err := strconv.Atoi(valor)
+		if err != nil {
+			responderErrorAdmin(w, http.StatusBadRequest, fmt.Sprintf("cantidad de instancias inválida: %s", valor))
+			return
+		}
+		instancias = cantidad
+	}
+
+	if err := crearRecurso(r.PathValue("nombre"), instancias); err != nil {
+		responderErrorAdmin(w, http.StatusConflict, err.Error())
+		return
+	}
+	utils.ResponderJSON(w, http.StatusCreated, map[string]interface{}{"status": "OK", "recurso": r.PathValue("nombre"), "instancias": instancias})
+}
+
+func adminListarIPC(w http.ResponseWriter, r *http.Request) {
+	utils.ResponderJSON(w, http.StatusOK, map[string]interface{}{"objetos": estadoObjetosIPC()})
+}
+
+// adminObtenerDeadlock devuelve el grafo de espera actual sin aplicar la política de recuperación
+func adminObtenerDeadlock(w http.ResponseWriter, r *http.Request) {
+	utils.ResponderJSON(w, http.StatusOK, construirGrafoEsperas())
+}
+
+// procesoDeRuta obtiene el PCB indicado en la ruta, respondiendo el error si no existe
+func procesoDeRuta(w http.ResponseWriter, r *http.Request) (*PCB, bool) {
+	pid, err := strconv.Atoi(r.PathValue("pid"))
+	if err != nil {
+		responderErrorAdmin(w, http.StatusBadRequest, fmt.Sprintf("PID inválido: %s", r.PathValue("pid")))
+		return nil, false
+	}
+
+	pcb := BuscarPCBPorPID(pid)
+	if pcb == nil {
+		responderErrorAdmin(w, http.StatusNotFound, fmt.Sprintf("no existe el proceso %d", pid))
+		return nil, false
+	}
+	return pcb, true
+}
+
+func responderErrorAdmin(w http.ResponseWriter, codigo int, mensaje string) {
+	utils.ResponderJSON(w, codigo, map[string]interface{}{"status": "ERROR", "mensaje": mensaje})
+}
+
+// pidPorCPU devuelve el PID que ejecuta cada CPU ocupada
+func pidPorCPU() map[string]int {
+	execMutex.Lock()
+	defer execMutex.Unlock()
+
+	ejecutando := make(map[string]int, len(colaExec))
+	for cpu, pcb := range colaExec {
+		if pcb != nil {
+			ejecutando[cpu] = pcb.PID
+		}
+	}
+	return ejecutando
+}
+
+// cpuPorPID devuelve la CPU en la que ejecuta cada proceso en EXEC
+func cpuPorPID() map[int]string {
+	cpus := map[int]string{}
+	for cpu, pid := range pidPorCPU() {
+		cpus[pid] = cpu
+	}
+	return cpus
+}
+
+func vistaProceso(pcb *PCB, cpu string) procesoAdmin {
+	padre, hijos, esperando := relacionesProceso(pcb)
+	hilos, esperandoHilo := hilosProceso(pcb)
+	contadorEstados, tiempoEstados := pcb.metricasEstado()
+	vista := procesoAdmin{
+		PID:               pcb.PID,
+		Estado:            pcb.Estado,
+		Archivo:           pcb.NombreArchivo,
+		Tamanio:           pcb.Tamanio,
+		PC:                pcb.PC,
+		Estimacion:        pcb.EstimacionSiguienteRafaga,
+		UltimaRafaga:      pcb.UltimaRafagaReal,
+		Ejecuciones:       pcb.TotalEjecuciones,
+		TiempoEjecucion:   pcb.TotalTiempoEjecucion,
+		Prioridad:         pcb.Prioridad,
+		NivelMLFQ:         pcb.NivelMLFQ,
+		Tickets:           pcb.Tickets,
+		MotivoBloqueo:     pcb.MotivoBloqueo,
+		EnSwap:            pcb.EnSwap,
+		CPU:               cpu,
+		HoraCreacion:      formatearHora(pcb.HoraCreacion),
+		HoraListo:         formatearHora(pcb.HoraListo),
+		HoraEjecucion:     formatearHora(pcb.HoraEjecucion),
+		HoraBloqueo:       formatearHora(pcb.HoraBloqueo),
+		TiempoReal:        pcb.TiempoReal,
+		DeadlinesPerdidos: pcb.DeadlinesPerdidos,
+		QuantumRestante:   pcb.QuantumRestante,
+		QuantumAsignado:   pcb.QuantumAsignado,
+		ContadorEstados:   contadorEstados,
+		TiempoEstados:     tiempoEstados,
+		TiempoRespuesta:   pcb.TiempoRespuesta(),
+		TiempoRetorno:     pcb.TiempoRetorno(),
+		TiempoEspera:      pcb.TiempoEspera(),
+		PadrePID:          padre,
+		Hijos:             hijos,
+		EsperandoHijo:     esperando,
+		Proceso:           pcb.PIDMemoria(),
+		TID:               pcb.TID,
+		Hilos:             hilos,
+	}
+	if esperandoHilo != sinEsperaHilo {
+		vista.EsperandoHilo = &esperandoHilo
+	}
+	if salida, hizoWait := resultadoUltimoWait(pcb); hizoWait {
+		vista.UltimoWait = &waitAdmin{PID: salida.PID, Estado: salida.Estado, Motivo: salida.Motivo}
+	}
+	return vista
+}
+
+// formatearHora devuelve la hora en RFC3339 con milisegundos, o vacío si no se registró
+func formatearHora(hora time.Time) string {
+	if hora.IsZero() {
+		return ""
+	}
+	return hora.Format("2006-01-02T15:04:05.000Z07:00")
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+// iniciarAging mejora periódicamente la prioridad de los procesos que esperan en READY
+// mientras el planificador siga en uso
+func iniciarAging(planificador Planificador) {
+	intervalo := time.Duration(configKernel().AgingInterval) * time.Millisecond
+	if intervalo <= 0 {
+		utils.InfoLog.Warn("INTERVALO_AGING no configurado, aging deshabilitado")
+		return
+	}
+
+	utils.InfoLog.Info("Iniciando aging de prioridades", "intervalo_ms", intervalo.Milliseconds())
+
+	for {
+		utils.Dormir(intervalo)
+		if !planificadorVigente(planificador) {
+			utils.InfoLog.Info("Aging detenido: el planificador fue reemplazado")
+			return
+		}
+		if aplicarAging(intervalo) {
+			// Un proceso mejoró su prioridad: el STS puede necesitar desalojar
+			condReady.Signal()
+		}
+	}
+}
+
+// aplicarAging mejora en uno la prioridad de cada proceso que esperó un intervalo completo en READY
+func aplicarAging(intervalo time.Duration) bool {
+	readyMutex.Lock()
+	defer readyMutex.Unlock()
+
+	ahora := utils.Ahora()
+	huboCambios := false
+	for _, pcb := range colaReady {
+		if pcb.Prioridad == 0 || ahora.Sub(pcb.UltimoAging) < intervalo {
+			continue
+		}
+
+		prioridadAnterior := pcb.Prioridad
+		pcb.Prioridad--
+		pcb.UltimoAging = ahora
+		huboCambios = true
+
+		utils.InfoLog.Info(fmt.Sprintf("(%d) - Aging: prioridad %d -> %d", pcb.PID, prioridadAnterior, pcb.Prioridad))
+	}
+	return huboCambios
+}
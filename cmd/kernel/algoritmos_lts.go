@@ -0,0 +1,157 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+func init() {
+	RegistrarPlanificador(TipoLargoPlazo, "FIFO", func() Planificador { return &planificadorFIFOLTS{} })
+	RegistrarPlanificador(TipoLargoPlazo, "PMCP", func() Planificador { return &planificadorPMCP{} })
+	RegistrarPlanificador(TipoLargoPlazo, "FIRST_FIT", func() Planificador { return &planificadorFirstFit{} })
+	RegistrarPlanificador(TipoLargoPlazo, "BEST_FIT", func() Planificador { return &planificadorBestFit{} })
+	RegistrarPlanificador(TipoLargoPlazo, "HRRN", func() Planificador { return &planificadorHRRN{} })
+}
+
+// planificadorFIFOLTS admite en orden de llegada a NEW
+type planificadorFIFOLTS struct {
+	planificadorBase
+}
+
+func (p *planificadorFIFOLTS) SeleccionarSiguiente() *PCB {
+	if len(colaNew) == 0 {
+		return nil
+	}
+	return colaNew[0]
+}
+
+// planificadorPMCP implementa Programación Multiprogramada Controlada por Prioridad (menor tamaño primero)
+type planificadorPMCP struct {
+	planificadorBase
+}
+
+func (p *planificadorPMCP) SeleccionarSiguiente() *PCB {
+	if len(colaNew) == 0 {
+		return nil
+	}
+
+	// Crear copia para ordenar
+	candidatos := make([]*PCB, len(colaNew))
+	copy(candidatos, colaNew)
+
+	// Ordenar por tamaño (menor tamaño = mayor prioridad)
+	sort.Slice(candidatos, func(i, j int) bool {
+		if candidatos[i].Tamanio == candidatos[j].Tamanio {
+			return candidatos[i].HoraCreacion.Before(candidatos[j].HoraCreacion)
+		}
+		return candidatos[i].Tamanio < candidatos[j].Tamanio
+	})
+
+	seleccionado := candidatos[0]
+	utils.InfoLog.Info("PMCP seleccionó proceso", "pid", seleccionado.PID, "tamaño", seleccionado.Tamanio)
+
+	return seleccionado
+}
+
+// procesosQueEntran devuelve, en orden de llegada, los procesos de NEW que entran en memoria
+// y registra los que se saltean
+func procesosQueEntran() []*PCB {
+	candidatos := []*PCB{}
+	for _, pcb := range colaNew {
+		if entraEnMemoria(pcb) {
+			candidatos = append(candidatos, pcb)
+		} else {
+			registrarIntentoAdmision(pcb, "SALTEADO_NO_ENTRA")
+		}
+	}
+	return candidatos
+}
+
+// rechazadoSinMemoria devuelve el primer proceso de NEW que no entra si ningún proceso ocupa memoria:
+// nadie puede liberar espacio, así que el LTS lo finaliza con MEMORIA_INSUFICIENTE igual que en FIFO y PMCP
+func rechazadoSinMemoria() *PCB {
+	if len(colaNew) == 0 || hayProcesosEnMemoria() {
+		return nil
+	}
+	for _, pcb := range colaNew {
+		if !entraEnMemoria(pcb) {
+			return pcb
+		}
+	}
+	return nil
+}
+
+// planificadorFirstFit admite al primer proceso de NEW que entra en memoria
+type planificadorFirstFit struct {
+	planificadorBase
+}
+
+func (p *planificadorFirstFit) SeleccionarSiguiente() *PCB {
+	candidatos := procesosQueEntran()
+	if len(candidatos) == 0 {
+		return rechazadoSinMemoria()
+	}
+	return candidatos[0]
+}
+
+// planificadorBestFit admite al proceso que mejor aprovecha el espacio libre (el más grande que entra)
+type planificadorBestFit struct {
+	planificadorBase
+}
+
+func (p *planificadorBestFit) SeleccionarSiguiente() *PCB {
+	candidatos := procesosQueEntran()
+	if len(candidatos) == 0 {
+		return rechazadoSinMemoria()
+	}
+
+	var seleccionado *PCB
+	for _, pcb := range candidatos {
+		if seleccionado == nil || pcb.Tamanio > seleccionado.Tamanio {
+			seleccionado = pcb
+		}
+	}
+
+	if seleccionado != nil {
+		utils.InfoLog.Info("BEST_FIT seleccionó proceso", "pid", seleccionado.PID, "tamaño", seleccionado.Tamanio, "espacio_disponible", espacioDisponibleLTS)
+	}
+	return seleccionado
+}
+
+// planificadorHRRN admite, entre los que entran en memoria, al de mayor tasa de respuesta:
+// (tiempo en NEW + ráfaga estimada) / ráfaga estimada
+type planificadorHRRN struct {
+	planificadorBase
+}
+
+func (p *planificadorHRRN) SeleccionarSiguiente() *PCB {
+	ahora := utils.Ahora()
+	tasaRespuesta := func(pcb *PCB) float64 {
+		servicio := pcb.EstimacionSiguienteRafaga
+		if servicio <= 0 {
+			servicio = 1
+		}
+		espera := float64(ahora.Sub(pcb.HoraCreacion).Milliseconds())
+		return (espera + servicio) / servicio
+	}
+
+	candidatos := procesosQueEntran()
+	if len(candidatos) == 0 {
+		return rechazadoSinMemoria()
+	}
+
+	var seleccionado *PCB
+	mejorTasa := 0.0
+	for _, pcb := range candidatos {
+		if tasa := tasaRespuesta(pcb); seleccionado == nil || tasa > mejorTasa {
+			seleccionado = pcb
+			mejorTasa = tasa
+		}
+	}
+
+	if seleccionado != nil {
+		utils.InfoLog.Info("HRRN seleccionó proceso", "pid", seleccionado.PID, "tasa_respuesta", mejorTasa)
+	}
+	return seleccionado
+}
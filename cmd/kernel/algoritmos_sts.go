@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+func init() {
+	RegistrarPlanificador(TipoCortoPlazo, "FIFO", func() Planificador { return &planificadorFIFO{} })
+	RegistrarPlanificador(TipoCortoPlazo, "SJF", func() Planificador { return &planificadorSJF{} })
+	RegistrarPlanificador(TipoCortoPlazo, "SRT", func() Planificador { return &planificadorSRT{} })
+	RegistrarPlanificador(TipoCortoPlazo, "RR", func() Planificador { return &planificadorRR{} })
+	RegistrarPlanificador(TipoCortoPlazo, "VRR", func() Planificador { return &planificadorVRR{} })
+	RegistrarPlanificador(TipoCortoPlazo, "PRIORIDADES", func() Planificador { return &planificadorPrioridades{} })
+}
+
+// planificadorFIFO despacha en orden de llegada a READY
+type planificadorFIFO struct {
+	planificadorBase
+}
+
+func (p *planificadorFIFO) SeleccionarSiguiente() *PCB {
+	if len(colaReady) == 0 {
+		return nil
+	}
+	return colaReady[0]
+}
+
+// planificadorSJF implementa Shortest Job First sin desalojo
+type planificadorSJF struct {
+	planificadorBase
+}
+
+func (p *planificadorSJF) SeleccionarSiguiente() *PCB {
+	if len(colaReady) == 0 {
+		return nil
+	}
+
+	candidatos := make([]*PCB, len(colaReady))
+	copy(candidatos, colaReady)
+
+	sort.Slice(candidatos, func(i, j int) bool {
+		if candidatos[i].EstimacionSiguienteRafaga == candidatos[j].EstimacionSiguienteRafaga {
+			return candidatos[i].HoraListo.Before(candidatos[j].HoraListo)
+		}
+		return candidatos[i].EstimacionSiguienteRafaga < candidatos[j].EstimacionSiguienteRafaga
+	})
+
+	seleccionado := candidatos[0]
+	utils.InfoLog.Info("SJF seleccionó proceso", "pid", seleccionado.PID, "estimacion", seleccionado.EstimacionSiguienteRafaga)
+
+	return seleccionado
+}
+
+// planificadorSRT implementa Shortest Remaining Time (SJF con desalojo)
+type planificadorSRT struct {
+	planificadorBase
+}
+
+func (p *planificadorSRT) SeleccionarSiguiente() *PCB {
+	if len(colaReady) == 0 {
+		return nil
+	}
+
+	mejorProceso := colaReady[0]
+	for _, pcb := range colaReady[1:] {
+		if pcb.EstimacionSiguienteRafaga < mejorProceso.EstimacionSiguienteRafaga {
+			mejorProceso = pcb
+		} else if pcb.EstimacionSiguienteRafaga == mejorProceso.EstimacionSiguienteRafaga {
+			if pcb.HoraListo.Before(mejorProceso.HoraListo) {
+				mejorProceso = pcb
+			}
+		}
+	}
+	return mejorProceso
+}
+
+func (p *planificadorSRT) DebeDesalojar(candidato *PCB, enEjecucion []*PCB) *PCB {
+	var procesoMasLargo *PCB
+	for _, pcbEnExec := range enEjecucion {
+		if candidato.EstimacionSiguienteRafaga < pcbEnExec.EstimacionSiguienteRafaga {
+			if procesoMasLargo == nil || pcbEnExec.EstimacionSiguienteRafaga > procesoMasLargo.EstimacionSiguienteRafaga {
+				procesoMasLargo = pcbEnExec
+			}
+		}
+	}
+
+	if procesoMasLargo != nil {
+		utils.InfoLog.Info(fmt.Sprintf("(%d) - Desalojado por algoritmo SJF/SRT", procesoMasLargo.PID))
+	}
+	return procesoMasLargo
+}
+
+// planificadorRR es FIFO con desalojo por fin de quantum
+type planificadorRR struct {
+	planificadorFIFO
+}
+
+func (p *planificadorRR) QuantumPara(pcb *PCB) float64 {
+	if configKernel().Quantum <= 0 {
+		return quantumPorDefecto
+	}
+	return float64(configKernel().Quantum)
+}
+
+// planificadorVRR prioriza a los procesos que volvieron de IO con quantum restante
+type planificadorVRR struct {
+	planificadorRR
+}
+
+func (p *planificadorVRR) SeleccionarSiguiente() *PCB {
+	if len(colaReadyPrioritaria) > 0 {
+		seleccionado := colaReadyPrioritaria[0]
+		utils.InfoLog.Info("VRR seleccionó proceso de cola prioritaria", "pid", seleccionado.PID, "quantum_restante", seleccionado.QuantumRestante)
+		return seleccionado
+	}
+	return p.planificadorRR.SeleccionarSiguiente()
+}
+
+func (p *planificadorVRR) AlPasarAReady(pcb *PCB) {
+	if pcb.QuantumRestante <= 0 {
+		colaReady = append(colaReady, pcb)
+		return
+	}
+	colaReadyPrioritaria = append(colaReadyPrioritaria, pcb)
+	utils.InfoLog.Info("Proceso encolado en READY prioritaria (VRR)", "pid", pcb.PID, "quantum_restante", pcb.QuantumRestante)
+}
+
+// AlBloquearse conserva el quantum no consumido del proceso
+func (p *planificadorVRR) AlBloquearse(pcb *PCB, motivo string) {
+	if pcb.QuantumAsignado <= 0 {
+		return
+	}
+
+	restante := pcb.QuantumAsignado - pcb.UltimaRafagaReal
+	pcb.QuantumAsignado = 0
+	if restante > 0 {
+		pcb.QuantumRestante = restante
+		utils.InfoLog.Info("Quantum restante conservado", "pid", pcb.PID, "restante_ms", restante)
+	}
+}
+
+func (p *planificadorVRR) QuantumPara(pcb *PCB) float64 {
+	if pcb.QuantumRestante > 0 {
+		return pcb.QuantumRestante
+	}
+	return p.planificadorRR.QuantumPara(pcb)
+}
+
+// planificadorPrioridades implementa prioridades con desalojo (menor número = mayor prioridad) y aging
+type planificadorPrioridades struct {
+	planificadorBase
+}
+
+func (p *planificadorPrioridades) SeleccionarSiguiente() *PCB {
+	if len(colaReady) == 0 {
+		return nil
+	}
+
+	candidato := colaReady[0]
+	for _, pcb := range colaReady[1:] {
+		if pcb.Prioridad < candidato.Prioridad ||
+			(pcb.Prioridad == candidato.Prioridad && pcb.HoraListo.Before(candidato.HoraListo)) {
+			candidato = pcb
+		}
+	}
+
+	utils.InfoLog.Info("Prioridades seleccionó proceso", "pid", candidato.PID, "prioridad", candidato.Prioridad)
+	return candidato
+}
+
+func (p *planificadorPrioridades) DebeDesalojar(candidato *PCB, enEjecucion []*PCB) *PCB {
+	var procesoADesalojar *PCB
+	for _, pcbEnExec := range enEjecucion {
+		if pcbEnExec.Prioridad > candidato.Prioridad {
+			if procesoADesalojar == nil || pcbEnExec.Prioridad > procesoADesalojar.Prioridad {
+				procesoADesalojar = pcbEnExec
+			}
+		}
+	}
+
+	if procesoADesalojar != nil {
+		utils.InfoLog.Info(fmt.Sprintf("(%d) - Desalojado por algoritmo de Prioridades", procesoADesalojar.PID))
+	}
+	return procesoADesalojar
+}
+
+func (p *planificadorPrioridades) IniciarSegundoPlano() {
+	go iniciarAging(p)
+}
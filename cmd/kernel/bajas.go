@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+// Las CPUs y dispositivos de IO avisan con MensajeBaja cuando se cierran. Sin drenar salen de los
+// pools en el momento: el proceso que estaba en la CPU vuelve a READY al terminar la instrucción en
+// curso. Drenando, una CPU ocupada deja de recibir procesos pero sigue hasta que termina la ráfaga en
+// curso, y recién entonces se quita y se le avisa con MensajeBajaCompletada. Los dispositivos de IO
+// drenan por su cuenta: se quitan del pool y terminan la petición en curso, y las que esperaban en
+// su cola pasan a otra instancia de la misma clase
+
+// cpusDrenando se protege con cpuClientsMutex
+var cpusDrenando = make(map[string]bool)
+
+// HandlerBaja atiende el aviso de baja de una CPU o un dispositivo de IO
+func HandlerBaja(msg *utils.Mensaje) (interface{}, error) {
+	datos, ok := msg.Datos.(map[string]interface{})
+	if !ok {
+		utils.ErrorLog.Error("Datos inválidos en baja", "datos", fmt.Sprintf("%v", msg.Datos))
+		return map[string]interface{}{"status": "ERROR", "message": "Datos inválidos"}, nil
+	}
+	drenar, _ := datos["drenar"].(bool)
+
+	if tipo, _ := datos["tipo"].(string); strings.HasPrefix(tipo, "IO") {
+		if !darDeBajaIO(tipo) {
+			return map[string]interface{}{"status": "ERROR", "message": fmt.Sprintf("IO '%s' no registrado", tipo)}, nil
+		}
+		return map[string]interface{}{"status": "OK"}, nil
+	}
+
+	if esCPU(msg.Origen, datos) {
+		nombre, _ := datos["identificador"].(string)
+		if nombre == "" {
+			nombre = msg.Origen
+		}
+		registrada, pendiente := darDeBajaCPU(nombre, drenar)
+		if !registrada {
+			return map[string]interface{}{"status": "ERROR", "message": fmt.Sprintf("CPU %s no registrada", nombre)}, nil
+		}
+		return map[string]interface{}{"status": "OK", "pendiente": pendiente}, nil
+	}
+
+	return map[string]interface{}{"status": "ERROR", "message": "Módulo desconocido"}, nil
+}
+
+// darDeBajaCPU quita la CPU del pool. Si drena y está ejecutando, queda pendiente hasta el fin de la ráfaga
+func darDeBajaCPU(nombre string, drenar bool) (registrada bool, pendiente bool) {
+	cpuClientsMutex.Lock()
+	defer cpuClientsMutex.Unlock()
+
+	if _, existe := cpuClients[nombre]; !existe {
+		return false, false
+	}
+
+	execMutex.Lock()
+	pcb, ocupada := colaExec[nombre]
+	execMutex.Unlock()
+
+	if drenar && ocupada {
+		cpusDrenando[nombre] = true
+		utils.InfoLog.Info("CPU drenando, se da de baja al terminar la ráfaga en curso", "cpu", nombre, "pid", pcb.PID)
+		return true, true
+	}
+
+	quitarCPU(nombre)
+	utils.InfoLog.Info("CPU dada de baja", "cpu", nombre, "drenar", drenar, "total_cpus", len(cpuClients))
+	return true, false
+}
+
+// completarDrenadoCPU se llama cuando la CPU deja de ejecutar un proceso: si estaba drenando, se quita
+// del pool y se le avisa que ya puede cerrarse
+func completarDrenadoCPU(nombre string) {
+	cpuClientsMutex.Lock()
+	cliente, existe := cpuClients[nombre]
+	if !existe || !cpusDrenando[nombre] {
+		cpuClientsMutex.Unlock()
+		return
+	}
+	quitarCPU(nombre)
+	cpuClientsMutex.Unlock()
+
+	utils.InfoLog.Info("CPU drenada y dada de baja", "cpu", nombre)
+	if _, err := cliente.EnviarHTTPMensaje(utils.MensajeBajaCompletada, "default", map[string]interface{}{}); err != nil {
+		utils.ErrorLog.Error("No se pudo avisar a la CPU que terminó de drenar", "cpu", nombre, "error", err)
+	}
+}
+
+// cpuAceptaProcesos indica si se le pueden despachar procesos: responde los latidos y no está drenando.
+// Requiere cpuClientsMutex tomado
+func cpuAceptaProcesos(nombre string) bool {
+	return cpuResponde(nombre) && !cpusDrenando[nombre]
+}
+
+// quitarCPU borra la CPU de los pools y detiene su monitor de latidos. Requiere cpuClientsMutex tomado
+func quitarCPU(nombre string) {
+	delete(cpuClients, nombre)
+	delete(monitoresCPU, nombre)
+	delete(cpusDrenando, nombre)
+}
+
+// darDeBajaIO quita el dispositivo con los mismos nombres con los que se registró
+func darDeBajaIO(tipoModulo string) bool {
+	existe := quitarDispositivoIO(tipoModulo)
+	if existe {
+		utils.InfoLog.Info("Dispositivo IO dado de baja", "nombre", tipoModulo)
+		reubicarColaIO(strings.TrimPrefix(tipoModulo, "IO"), false)
+	}
+	return existe
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+// Cada instancia de IO atiende una petición por vez. Las demás esperan en una cola FIFO propia de la
+// instancia y la siguiente se envía cuando llega el IO_TERMINADA de la que estaba en curso. Si la
+// instancia se cae o se da de baja, las peticiones que esperaban pasan a otra instancia de la misma clase
+
+// peticionIO es una petición de IO de un proceso bloqueado
+type peticionIO struct {
+	pcb    *PCB
+	tiempo int
+}
+
+// colaIO guarda la petición que atiende una instancia y las que esperan su turno
+type colaIO struct {
+	enCurso   *peticionIO
+	esperando []*peticionIO
+}
+
+var (
+	colasIO      = make(map[string]*colaIO)
+	colasIOMutex sync.Mutex
+)
+
+// encolarIO envía la petición si el dispositivo está libre o la deja esperando su turno
+func encolarIO(pcb *PCB, dispositivo string, tiempo int) {
+	if procesoFinalizado(pcb) {
+		return
+	}
+	dispositivo = nombreInstanciaIO(dispositivo)
+	if _, existe := ObtenerClienteIO(dispositivo); !existe {
+		alternativo, hayAlternativo := otraInstanciaIO(dispositivo)
+		if !hayAlternativo {
+			utils.ErrorLog.Error("Dispositivo IO no registrado, finalizando proceso", "dispositivo", dispositivo, "pid", pcb.PID)
+			FinalizarProceso(pcb, "ERROR_IO_DEVICE_NOT_FOUND") // Finaliza si el dispositivo no existe
+			return
+		}
+		utils.InfoLog.Warn("Dispositivo IO no registrado, se usa otra instancia", "pid", pcb.PID, "dispositivo", dispositivo, "alternativo", alternativo)
+		dispositivo = alternativo
+	}
+
+	peticion := &peticionIO{pcb: pcb, tiempo: tiempo}
+
+	colasIOMutex.Lock()
+	cola, existe := colasIO[dispositivo]
+	if !existe {
+		cola = &colaIO{}
+		colasIO[dispositivo] = cola
+	}
+	if cola.enCurso != nil {
+		cola.esperando = append(cola.esperando, peticion)
+		posicion := len(cola.esperando)
+		colasIOMutex.Unlock()
+		utils.InfoLog.Info(fmt.Sprintf("(%d) - Espera el dispositivo %s (posición %d)", pcb.PID, dispositivo, posicion))
+		return
+	}
+	cola.enCurso = peticion
+	colasIOMutex.Unlock()
+
+	go EnviarSolicitudIO(pcb, dispositivo, tiempo)
+}
+
+// avanzarColaIO libera el dispositivo que atendía al proceso y envía la siguiente petición de su cola
+func avanzarColaIO(pid int) {
+	colasIOMutex.Lock()
+	for dispositivo, cola := range colasIO {
+		if cola.enCurso == nil || cola.enCurso.pcb.PID != pid {
+			continue
+		}
+
+		cola.enCurso = nil
+		for len(cola.esperando) > 0 {
+			siguiente := cola.esperando[0]
+			cola.esperando = cola.esperando[1:]
+			if siguiente.pcb.Estado != EstadoExit {
+				cola.enCurso = siguiente
+				break
+			}
+		}
+		siguiente := cola.enCurso
+		if siguiente == nil {
+			delete(colasIO, dispositivo)
+		}
+		colasIOMutex.Unlock()
+
+		if siguiente != nil {
+			go EnviarSolicitudIO(siguiente.pcb, dispositivo, siguiente.tiempo)
+		}
+		return
+	}
+	colasIOMutex.Unlock()
+}
+
+// reubicarColaIO pasa las peticiones que esperaban en el dispositivo a otras instancias de la misma
+// clase. Con incluirEnCurso también reenvía la que estaba atendiendo, porque no va a terminar
+func reubicarColaIO(dispositivo string, incluirEnCurso bool) {
+	colasIOMutex.Lock()
+	cola, existe := colasIO[dispositivo]
+	if !existe {
+		colasIOMutex.Unlock()
+		return
+	}
+	pendientes := cola.esperando
+	cola.esperando = nil
+	if incluirEnCurso && cola.enCurso != nil {
+		pendientes = append([]*peticionIO{cola.enCurso}, pendientes...)
+		cola.enCurso = nil
+	}
+	if cola.enCurso == nil {
+		delete(colasIO, dispositivo)
+	}
+	colasIOMutex.Unlock()
+
+	for _, peticion := range pendientes {
+		if peticion.pcb.Estado == EstadoExit {
+			continue
+		}
+
+		alternativo, hayAlternativo := otraInstanciaIO(dispositivo)
+		if !hayAlternativo {
+			utils.ErrorLog.Error("No queda ninguna instancia del dispositivo, finalizando proceso", "clase", claseDispositivo(dispositivo), "pid", peticion.pcb.PID)
+			FinalizarProceso(peticion.pcb, "ERROR_IO_CONNECTION")
+			continue
+		}
+
+		utils.InfoLog.Info(fmt.Sprintf("(%d) - Petición de IO reenviada de %s a %s", peticion.pcb.PID, dispositivo, alternativo))
+		encolarIO(peticion.pcb, alternativo, peticion.tiempo)
+	}
+}
+
+// descartarPeticionesIO saca de las colas de espera al proceso que finalizó. Si su petición ya estaba
+// en curso, el dispositivo sigue ocupado hasta que avise IO_TERMINADA
+func descartarPeticionesIO(pid int) {
+	colasIOMutex.Lock()
+	defer colasIOMutex.Unlock()
+
+	for _, cola := range colasIO {
+		for i, peticion := range cola.esperando {
+			if peticion.pcb.PID == pid {
+				cola.esperando = append(cola.esperando[:i], cola.esperando[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// esperandoIO devuelve los PIDs en espera de cada dispositivo, en orden de llegada
+func esperandoIO() map[string][]int {
+	colasIOMutex.Lock()
+	defer colasIOMutex.Unlock()
+
+	esperando := make(map[string][]int, len(colasIO))
+	for dispositivo, cola := range colasIO {
+		pids := make([]int, 0, len(cola.esperando))
+		for _, peticion := range cola.esperando {
+			pids = append(pids, peticion.pcb.PID)
+		}
+		esperando[dispositivo] = pids
+	}
+	return esperando
+}
+
+// nombreInstanciaIO traduce el alias "IO<nombre>" al nombre de la instancia, para que ambos compartan cola
+func nombreInstanciaIO(nombre string) string {
+	dispositivosIOMutex.RLock()
+	defer dispositivosIOMutex.RUnlock()
+	if esAliasIO(nombre) {
+		return strings.TrimPrefix(nombre, "IO")
+	}
+	return nombre
+}
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+const ayudaConsola = `Comandos disponibles:
+  INICIAR                                  Inicia o reanuda la planificación (ENTER equivale a INICIAR)
+  DETENER                                  Pausa la planificación (los procesos en CPU terminan su ráfaga)
+  CREAR <archivo> <tamaño> [prioridad] [CLAVE=VALOR...]
+                                           Crea un proceso en NEW (opciones: TICKETS, PERIODO, DEADLINE, WCET)
+  MATAR <pid> [ARBOL]                      Finaliza un proceso (ARBOL: también todos sus descendientes)
+  ESTADO [pid]                             Muestra las colas o el detalle de un proceso
+  MULTIPROGRAMACION <grado>                Cambia el grado de multiprogramación
+  RECURSOS [nombre instancias]             Muestra los recursos o crea uno nuevo
+  IPC                                      Muestra las colas de mensajes y pipes
+  DEADLOCK                                 Detecta deadlocks ahora y aplica la política de recuperación
+  METRICAS                                 Muestra métricas de los procesos vivos y del sistema
+  REPORTE                                  Muestra el reporte agregado de la corrida y lo guarda en JSON
+  GANTT [ASCII|CHROME] [traza.jsonl]       Dibuja el diagrama de Gantt de la traza (la actual o la de un archivo);
+                                           CHROME lo exporta en formato trace-event de Chrome
+  AYUDA                                    Muestra esta ayuda
+  SALIR                                    Finaliza el Kernel`
+
+// ejecutarConsola lee comandos línea por línea hasta fin de entrada.
+// Si la entrada termina sin haber iniciado la planificación, la inicia (comportamiento del ENTER original)
+func ejecutarConsola(entrada io.Reader) {
+	lector := bufio.NewScanner(entrada)
+	fmt.Print("> ")
+	for lector.Scan() {
+		ejecutarComandoConsola(lector.Text())
+		fmt.Print("> ")
+	}
+
+	utils.InfoLog.Info("Fin de la entrada de la consola")
+	if !planificadoresEnMarcha {
+		iniciarPlanificadores()
+	}
+}
+
+// ejecutarComandoConsola interpreta un comando de la consola
+func ejecutarComandoConsola(linea string) {
+	campos := strings.Fields(linea)
+	if len(campos) == 0 {
+		// ENTER solo inicia los planificadores la primera vez
+		if !planificadoresEnMarcha {
+			comandoIniciar()
+		}
+		return
+	}
+
+	comando := strings.ToUpper(campos[0])
+	argumentos := campos[1:]
+	utils.InfoLog.Info("Comando de consola", "comando", comando, "argumentos", argumentos)
+
+	switch comando {
+	case "INICIAR":
+		comandoIniciar()
+	case "DETENER":
+		detenerPlanificacion()
+		fmt.Println("Planificación detenida")
+	case "CREAR":
+		comandoCrear(argumentos)
+	case "MATAR":
+		comandoMatar(argumentos)
+	case "ESTADO":
+		comandoEstado(argumentos)
+	case "MULTIPROGRAMACION":
+		comandoMultiprogramacion(argumentos)
+	case "RECURSOS":
+		comandoRecursos(argumentos)
+	case "IPC":
+		comandoIPC()
+	case "DEADLOCK":
+		comandoDeadlock()
+	case "METRICAS":
+		comandoMetricas()
+	case "REPORTE":
+		fmt.Println(emitirReporte())
+	case "GANTT":
+		comandoGantt(argumentos)
+	case "AYUDA":
+		fmt.Println(ayudaConsola)
+	case "SALIR":
+		utils.InfoLog.Info("SALIR ingresado en consola. Finalizando Kernel")
+		fmt.Println(emitirReporte())
+		fmt.Println("Kernel finalizando...")
+		os.Exit(0)
+	default:
+		fmt.Printf("Comando desconocido: %s (AYUDA para ver los comandos)\n", campos[0])
+	}
+}
+
+func comandoIniciar() {
+	if !planificadoresEnMarcha {
+		iniciarPlanificadores()
+		fmt.Println("Planificadores iniciados. Sistema funcionando...")
+		return
+	}
+	reanudarPlanificacion()
+	fmt.Println("Planificación reanudada")
+}
+
+func comandoCrear(argumentos []string) {
+	if len(argumentos) < 2 {
+		fmt.Println("Uso: CREAR <archivo> <tamaño> [prioridad] [CLAVE=VALOR...]")
+		return
+	}
+
+	tamanio, err := strconv.Atoi(argumentos[1])
+	if err != nil || tamanio < 0 {
+		fmt.Printf("Tamaño inválido: %s\n", argumentos[1])
+		return
+	}
+
+	prioridad, opciones, err := parsearParametrosProceso(argumentos[2:])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	pcb := NuevoPCB(-1, tamanio)
+	pcb.NombreArchivo = argumentos[0]
+	pcb.AsignarPrioridad(prioridad)
+	asignarTickets(pcb, opciones)
+	asignarOpcionesTiempoReal(pcb, opciones)
+	AgregarProcesoANew(pcb)
+
+	fmt.Printf("Proceso %d creado en NEW (%s, %d bytes)\n", pcb.PID, pcb.NombreArchivo, pcb.Tamanio)
+}
+
+func comandoMatar(argumentos []string) {
+	if len(argumentos) < 1 {
+		fmt.Println("Uso: MATAR <pid> [ARBOL]")
+		return
+	}
+
+	pid, err := strconv.Atoi(argumentos[0])
+	if err != nil {
+		fmt.Printf("PID inválido: %s\n", argumentos[0])
+		return
+	}
+
+	pcb := BuscarPCBPorPID(pid)
+	if pcb == nil {
+		fmt.Printf("No existe el proceso %d\n", pid)
+		return
+	}
+
+	if len(argumentos) > 1 && strings.ToUpper(argumentos[1]) == "ARBOL" {
+		finalizados := matarArbolProcesos(pcb, "FINALIZADO_POR_CONSOLA")
+		fmt.Printf("Procesos finalizados: %v\n", finalizados)
+		return
+	}
+
+	matarProceso(pcb, "FINALIZADO_POR_CONSOLA")
+	fmt.Printf("Proceso %d finalizado\n", pid)
+}
+
+func comandoEstado(argumentos []string) {
+	if len(argumentos) == 0 {
+		for _, linea := range describirColas() {
+			fmt.Println(linea)
+		}
+		return
+	}
+
+	pid, err := strconv.Atoi(argumentos[0])
+	if err != nil {
+		fmt.Printf("PID inválido: %s\n", argumentos[0])
+		return
+	}
+
+	pcb := BuscarPCBPorPID(pid)
+	if pcb == nil {
+		fmt.Printf("No existe el proceso %d\n", pid)
+		return
+	}
+
+	fmt.Printf("PID %d - Estado: %s - Archivo: %s - Tamaño: %d - PC: %d\n", pcb.PID, pcb.Estado, pcb.NombreArchivo, pcb.Tamanio, pcb.PC)
+	fmt.Printf("  Estimación: %.2f ms - Última ráfaga: %.2f ms - Ráfagas: %d - CPU total: %.2f ms\n",
+		pcb.EstimacionSiguienteRafaga, pcb.UltimaRafagaReal, pcb.TotalEjecuciones, pcb.TotalTiempoEjecucion)
+	fmt.Printf("  Prioridad: %d (base %d) - Nivel MLFQ: %d - Tickets: %d\n", pcb.Prioridad, pcb.PrioridadBase, pcb.NivelMLFQ, pcb.Tickets)
+	if pcb.TiempoReal {
+		fmt.Printf("  Tiempo real: período %.0f ms, deadline %.0f ms, WCET %.0f ms, deadlines perdidos %d\n", pcb.Periodo, pcb.Deadline, pcb.WCET, pcb.DeadlinesPerdidos)
+	}
+	if pcb.MotivoBloqueo != "" {
+		fmt.Printf("  Último motivo de bloqueo: %s\n", pcb.MotivoBloqueo)
+	}
+	padre, hijos, esperando := relacionesProceso(pcb)
+	fmt.Printf("  Padre: %d - Hijos: %v", padre, hijos)
+	if esperando != "" {
+		fmt.Printf(" - Esperando hijo: %s", esperando)
+	}
+	fmt.Println()
+	if salida, hizoWait := resultadoUltimoWait(pcb); hizoWait {
+		fmt.Printf("  Último WAIT: hijo %d con estado %d\n", salida.PID, salida.Estado)
+	}
+	hilos, esperandoHilo := hilosProceso(pcb)
+	fmt.Printf("  Proceso: %d - TID: %d - Hilos: %v", pcb.PIDMemoria(), pcb.TID, hilos)
+	if esperandoHilo != sinEsperaHilo {
+		fmt.Printf(" - Esperando hilo: %d", esperandoHilo)
+	}
+	fmt.Println()
+}
+
+func comandoMultiprogramacion(argumentos []string) {
+	if len(argumentos) < 1 {
+		enUso, capacidad := semaforoMultiprogram.Estado()
+		fmt.Printf("Grado de multiprogramación: %d (en uso: %d)\n", capacidad, enUso)
+		return
+	}
+
+	grado, err := strconv.Atoi(argumentos[0])
+	if err != nil || grado <= 0 {
+		fmt.Printf("Grado inválido: %s\n", argumentos[0])
+		return
+	}
+
+	recargaMutex.Lock()
+	configKernel().GradoMultiprogramacion = grado
+	redimensionarMultiprogramacion(grado)
+	recargaMutex.Unlock()
+	condNew.Broadcast()
+
+	fmt.Printf("Grado de multiprogramación: %d\n", grado)
+}
+
+func comandoRecursos(argumentos []string) {
+	if len(argumentos) >= 2 {
+		instancias, err := strconv.Atoi(argumentos[1])
+		if err != nil {
+			fmt.Printf("Cantidad de instancias inválida: %s\n", argumentos[1])
+			return
+		}
+		if err := crearRecurso(argumentos[0], instancias); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("Recurso %s creado con %d instancias\n", argumentos[0], instancias)
+		return
+	}
+
+	estados := estadoRecursos()
+	if len(estados) == 0 {
+		fmt.Println("No hay recursos definidos")
+		return
+	}
+	fmt.Printf("Recursos (desbloqueo %s):\n", ordenRecursos())
+	for _, recurso := range estados {
+		fmt.Printf("  %s - disponibles %d/%d - asignados %v - bloqueados %v\n",
+			recurso.Nombre, recurso.Disponibles, recurso.Instancias, recurso.Asignados, recurso.Bloqueados)
+	}
+}
+
+func comandoIPC() {
+	estados := estadoObjetosIPC()
+	if len(estados) == 0 {
+		fmt.Println("No hay colas ni pipes abiertos")
+		return
+	}
+	for _, objeto := range estados {
+		fmt.Printf("  %s (%s, capacidad %d) - mensajes %d - bytes %d - abierto por %v - emisores bloqueados %v - receptores bloqueados %v\n",
+			objeto.Nombre, objeto.Tipo, objeto.Capacidad, objeto.Mensajes, objeto.Bytes, objeto.Abiertos, objeto.Emisores, objeto.Receptores)
+	}
+}
+
+func comandoDeadlock() {
+	grafo := verificarDeadlocks()
+	if len(grafo.Deadlock) == 0 {
+		fmt.Println("No hay deadlock")
+		return
+	}
+
+	fmt.Printf("Deadlock entre los procesos %v (recuperación %s)\n", grafo.Deadlock, recuperacionDeadlock())
+	if len(grafo.Ciclo) > 0 {
+		fmt.Printf("  Ciclo: %v\n", grafo.Ciclo)
+	}
+	for _, pid := range grafo.Deadlock {
+		fmt.Printf("  %d espera %s - tiene %v\n", pid, grafo.Esperas[pid], grafo.Asignaciones[pid])
+	}
+}
+
+func comandoMetricas() {
+	mapaMutex.RLock()
+	procesos := make([]*PCB, 0, len(mapaPCBs))
+	for _, pcb := range mapaPCBs {
+		procesos = append(procesos, pcb)
+	}
+	mapaMutex.RUnlock()
+	sort.Slice(procesos, func(i, j int) bool { return procesos[i].PID < procesos[j].PID })
+
+	enUso, capacidad := semaforoMultiprogram.Estado()
+	tiempoRealMutex.Lock()
+	perdidos := deadlinesPerdidosTotales
+	tiempoRealMutex.Unlock()
+
+	fmt.Printf("Sistema: algoritmo %s / %s - multiprogramación %d/%d - CPU total %.2f ms - deadlines perdidos %d - finalizados %d\n",
+		configKernel().SchedulerAlgorithm, configKernel().ReadyIngressAlgorithm, enUso, capacidad, tiempoCPUSistema(), perdidos, cantidadFinalizados())
+
+	for _, pcb := range procesos {
+		fmt.Printf("  PID %d - %s - ráfagas %d - CPU %.2f ms - estimación %.2f ms - PC %d\n",
+			pcb.PID, pcb.Estado, pcb.TotalEjecuciones, pcb.TotalTiempoEjecucion, pcb.EstimacionSiguienteRafaga, pcb.PC)
+	}
+}
+
+func comandoGantt(argumentos []string) {
+	formato := "ASCII"
+	if len(argumentos) > 0 {
+		formato = strings.ToUpper(argumentos[0])
+	}
+
+	eventos := eventosRegistrados()
+	origen := rutaTraza()
+	if len(argumentos) > 1 {
+		origen = argumentos[1]
+		var err error
+		if eventos, err = leerTraza(origen); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	switch formato {
+	case "ASCII":
+		fmt.Println(renderizarGanttASCII(eventos, anchoGanttPorDefecto))
+	case "CHROME":
+		datos, err := exportarTrazaChrome(eventos)
+		if err != nil {
+			fmt.Printf("Error exportando traza: %v\n", err)
+			return
+		}
+		destino := strings.TrimSuffix(origen, ".jsonl") + "-chrome.json"
+		if err := os.WriteFile(destino, datos, 0644); err != nil {
+			fmt.Printf("Error escribiendo %s: %v\n", destino, err)
+			return
+		}
+		fmt.Printf("Traza exportada a %s (abrir con chrome://tracing o Perfetto)\n", destino)
+	default:
+		fmt.Println("Uso: GANTT [ASCII|CHROME] [traza.jsonl]")
+	}
+}
+
+// describirColas devuelve una línea por cola con los PIDs que contiene
+func describirColas() []string {
+	lineas := []string{}
+
+	newMutex.Lock()
+	lineas = append(lineas, fmt.Sprintf("NEW: %v", pidsDeCola(colaNew)))
+	newMutex.Unlock()
+
+	readyMutex.Lock()
+	lineas = append(lineas, fmt.Sprintf("READY: %v", pidsDeCola(colaReady)))
+	if len(colaReadyPrioritaria) > 0 {
+		lineas = append(lineas, fmt.Sprintf("READY prioritaria (VRR): %v", pidsDeCola(colaReadyPrioritaria)))
+	}
+	for nivel, cola := range colasMLFQ {
+		lineas = append(lineas, fmt.Sprintf("READY nivel %d (MLFQ): %v", nivel, pidsDeCola(cola)))
+	}
+	readyMutex.Unlock()
+
+	execMutex.Lock()
+	cpus := make([]string, 0, len(colaExec))
+	for cpu, pcb := range colaExec {
+		cpus = append(cpus, fmt.Sprintf("%s=%d", cpu, pcb.PID))
+	}
+	execMutex.Unlock()
+	sort.Strings(cpus)
+	lineas = append(lineas, fmt.Sprintf("EXEC: %v", cpus))
+
+	blockedMutex.Lock()
+	lineas = append(lineas, fmt.Sprintf("BLOCKED: %v", pidsDeCola(colaBlocked)))
+	blockedMutex.Unlock()
+
+	suspReadyMutex.Lock()
+	lineas = append(lineas, fmt.Sprintf("SUSP. READY: %v", pidsDeCola(colaSuspReady)))
+	suspReadyMutex.Unlock()
+
+	suspBlockedMutex.Lock()
+	lineas = append(lineas, fmt.Sprintf("SUSP. BLOCKED: %v", pidsDeCola(colaSuspBlocked)))
+	suspBlockedMutex.Unlock()
+
+	lineas = append(lineas, fmt.Sprintf("EXIT: %d procesos", cantidadFinalizados()))
+
+	if planificacionEstaDetenida() {
+		lineas = append(lineas, "Planificación DETENIDA")
+	}
+	return lineas
+}
+
+// pidsDeCola devuelve los PIDs de una cola en orden
+func pidsDeCola(cola []*PCB) []int {
+	pids := make([]int, len(cola))
+	for i, pcb := range cola {
+		pids[i] = pcb.PID
+	}
+	return pids
+}
+
+// cantidadFinalizados devuelve la cantidad de procesos en EXIT
+func cantidadFinalizados() int {
+	exitMutex.Lock()
+	defer exitMutex.Unlock()
+	return len(colaExit)
+}
+
+// parsearParametrosProceso interpreta los parámetros opcionales de creación de un proceso:
+// una prioridad numérica y opciones CLAVE=VALOR. Devuelve -1 si no se indicó prioridad
+func parsearParametrosProceso(argumentos []string) (int, map[string]interface{}, error) {
+	prioridad := -1
+	opciones := map[string]interface{}{}
+
+	for _, extra := range argumentos {
+		if clave, valor, esOpcion := strings.Cut(extra, "="); esOpcion {
+			numero, err := strconv.Atoi(valor)
+			if err != nil || numero < 0 {
+				return 0, nil, fmt.Errorf("la opción %s debe tener un valor entero no negativo", extra)
+			}
+			opciones[strings.ToUpper(clave)] = float64(numero)
+			continue
+		}
+
+		numero, err := strconv.Atoi(extra)
+		if err != nil || numero < 0 {
+			return 0, nil, fmt.Errorf("la prioridad debe ser un entero no negativo: %s", extra)
+		}
+		prioridad = numero
+	}
+	return prioridad, opciones, nil
+}
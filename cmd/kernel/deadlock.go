@@ -0,0 +1,470 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+// Modos de detección de deadlock (DETECCION_DEADLOCK)
+const (
+	DeteccionNinguna    = "NINGUNA"
+	DeteccionAlBloquear = "AL_BLOQUEAR" // Se verifica cada vez que un proceso se bloquea por un recurso
+	DeteccionPeriodica  = "PERIODICA"   // Se verifica cada INTERVALO_DEADLOCK ms
+)
+
+// Políticas de recuperación (RECUPERACION_DEADLOCK)
+const (
+	RecuperacionReportar       = "REPORTAR"        // Solo se informa el deadlock
+	RecuperacionMasJoven       = "MAS_JOVEN"       // Se finaliza el proceso creado más recientemente
+	RecuperacionMenorPrioridad = "MENOR_PRIORIDAD" // Se finaliza el proceso de menor prioridad (mayor número)
+)
+
+const intervaloDeadlockPorDefecto = 1000
+
+var (
+	// reclamosMaximos guarda las instancias máximas que cada proceso declaró con CLAIM (protegido por recursosMutex)
+	reclamosMaximos = make(map[int]map[string]int)
+
+	// deadlockMutex serializa las verificaciones para que dos recuperaciones no elijan víctimas a la vez
+	deadlockMutex           sync.Mutex
+	ultimoDeadlockReportado string
+
+	deteccionPeriodicaActiva bool
+	deteccionPeriodicaMutex  sync.Mutex
+)
+
+// deteccionDeadlock devuelve el modo de detección configurado
+func deteccionDeadlock() string {
+	switch strings.ToUpper(configKernel().DeadlockDetection) {
+	case DeteccionAlBloquear:
+		return DeteccionAlBloquear
+	case DeteccionPeriodica:
+		return DeteccionPeriodica
+	default:
+		return DeteccionNinguna
+	}
+}
+
+// recuperacionDeadlock devuelve la política de recuperación configurada
+func recuperacionDeadlock() string {
+	switch strings.ToUpper(configKernel().DeadlockRecovery) {
+	case RecuperacionMasJoven:
+		return RecuperacionMasJoven
+	case RecuperacionMenorPrioridad:
+		return RecuperacionMenorPrioridad
+	default:
+		return RecuperacionReportar
+	}
+}
+
+// usaBanquero indica si los pedidos de recursos se evitan con el algoritmo del banquero
+func usaBanquero() bool {
+	return strings.ToUpper(configKernel().DeadlockAvoidance) == "BANQUERO"
+}
+
+// === Evitación: algoritmo del banquero ===
+
+// declararReclamo registra el máximo de instancias de un recurso que el proceso puede llegar a pedir
+func declararReclamo(pcb *PCB, nombre string, cantidad int) error {
+	recursosMutex.Lock()
+	defer recursosMutex.Unlock()
+
+	recurso, existe := recursos[nombre]
+	if !existe {
+		return fmt.Errorf("el recurso %s no existe", nombre)
+	}
+	if cantidad < 0 || cantidad > recurso.Instancias {
+		return fmt.Errorf("reclamo de %d instancias de %s fuera de rango (0-%d)", cantidad, nombre, recurso.Instancias)
+	}
+	if cantidad < recurso.Asignados[pcb.PID] {
+		return fmt.Errorf("el proceso ya tiene %d instancias de %s", recurso.Asignados[pcb.PID], nombre)
+	}
+
+	if reclamosMaximos[pcb.PID] == nil {
+		reclamosMaximos[pcb.PID] = make(map[string]int)
+	}
+	reclamosMaximos[pcb.PID][nombre] = cantidad
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - Declara reclamo máximo de %d instancias de %s", pcb.PID, cantidad, nombre))
+	return nil
+}
+
+// reclamoMaximo devuelve el máximo que el proceso puede pedir del recurso. Quien declaró reclamos solo puede
+// pedir lo declarado; quien no declaró nada se considera que puede pedir todas las instancias.
+// Requiere recursosMutex tomado
+func reclamoMaximo(pid int, recurso *Recurso) int {
+	if reclamos, declaro := reclamosMaximos[pid]; declaro {
+		return reclamos[recurso.Nombre]
+	}
+	return recurso.Instancias
+}
+
+// excedeReclamo indica si una instancia más del recurso supera el reclamo declarado. Requiere recursosMutex tomado
+func excedeReclamo(pid int, recurso *Recurso) bool {
+	if !usaBanquero() {
+		return false
+	}
+	return recurso.Asignados[pid]+1 > reclamoMaximo(pid, recurso)
+}
+
+// asignacionSegura simula asignar una instancia del recurso al proceso y verifica que el sistema quede en
+// estado seguro. Sin BANQUERO toda asignación es segura. Requiere recursosMutex tomado
+func asignacionSegura(pid int, recurso *Recurso) bool {
+	if !usaBanquero() {
+		return true
+	}
+
+	asignarInstancia(pid, recurso)
+	seguro := estadoSeguro()
+	recurso.Disponibles++
+	recurso.Asignados[pid]--
+	if recurso.Asignados[pid] == 0 {
+		delete(recurso.Asignados, pid)
+	}
+
+	if !seguro {
+		utils.InfoLog.Info("Banquero: asignación postergada por estado inseguro", "pid", pid, "recurso", recurso.Nombre)
+	}
+	return seguro
+}
+
+// estadoSeguro busca una secuencia en la que todos los procesos con recursos o reclamos puedan terminar
+// recibiendo lo que les falta de su reclamo máximo. Requiere recursosMutex tomado
+func estadoSeguro() bool {
+	disponibles := map[string]int{}
+	procesos := map[int]bool{}
+	for nombre, recurso := range recursos {
+		disponibles[nombre] = recurso.Disponibles
+		for pid := range recurso.Asignados {
+			procesos[pid] = true
+		}
+	}
+	for pid := range reclamosMaximos {
+		procesos[pid] = true
+	}
+
+	pendientes := make([]int, 0, len(procesos))
+	for pid := range procesos {
+		pendientes = append(pendientes, pid)
+	}
+	sort.Ints(pendientes)
+
+	for len(pendientes) > 0 {
+		avanzo := false
+		for i, pid := range pendientes {
+			puedeTerminar := true
+			for nombre, recurso := range recursos {
+				if reclamoMaximo(pid, recurso)-recurso.Asignados[pid] > disponibles[nombre] {
+					puedeTerminar = false
+					break
+				}
+			}
+			if !puedeTerminar {
+				continue
+			}
+
+			for nombre, recurso := range recursos {
+				disponibles[nombre] += recurso.Asignados[pid]
+			}
+			pendientes = append(pendientes[:i], pendientes[i+1:]...)
+			avanzo = true
+			break
+		}
+		if !avanzo {
+			return false
+		}
+	}
+	return true
+}
+
+// === Detección y recuperación ===
+
+// GrafoEsperas es el grafo de espera entre procesos: cada proceso bloqueado apunta a los que debe esperar
+type GrafoEsperas struct {
+	Esperas      map[int]string   `json:"esperas"`      // Qué espera cada proceso bloqueado (recurso, hijo o hilo)
+	Aristas      map[int][]int    `json:"aristas"`      // PID bloqueado -> PIDs que tienen lo que espera
+	Asignaciones map[int][]string `json:"asignaciones"` // Recursos tomados por cada proceso
+	Deadlock     []int            `json:"deadlock"`     // Procesos que no pueden avanzar
+	Ciclo        []int            `json:"ciclo,omitempty"`
+}
+
+// construirGrafoEsperas arma el grafo de asignación/espera y detecta los procesos en deadlock por reducción:
+// se van descartando los procesos que pueden avanzar con lo disponible (devolviendo lo que tienen) y los
+// que quedan son los que están en deadlock. Sirve también para recursos de varias instancias, para WAIT de hijos
+// y para THREAD_JOIN
+func construirGrafoEsperas() GrafoEsperas {
+	recursosMutex.Lock()
+	defer recursosMutex.Unlock()
+	jerarquiaMutex.Lock()
+	defer jerarquiaMutex.Unlock()
+	hilosMutex.Lock()
+	defer hilosMutex.Unlock()
+
+	grafo := GrafoEsperas{
+		Esperas:      map[int]string{},
+		Aristas:      map[int][]int{},
+		Asignaciones: map[int][]string{},
+		Deadlock:     []int{},
+	}
+
+	mapaMutex.RLock()
+	vivos := make(map[int]*PCB, len(mapaPCBs))
+	for pid, pcb := range mapaPCBs {
+		vivos[pid] = pcb
+	}
+	mapaMutex.RUnlock()
+
+	disponibles := map[string]int{}
+	esperaRecurso := map[int]string{}
+	for nombre, recurso := range recursos {
+		disponibles[nombre] = recurso.Disponibles
+		for pid := range recurso.Asignados {
+			grafo.Asignaciones[pid] = append(grafo.Asignaciones[pid], nombre)
+		}
+		for _, pcb := range recurso.Bloqueados {
+			esperaRecurso[pcb.PID] = nombre
+			grafo.Esperas[pcb.PID] = "RECURSO " + nombre
+			for pid := range recurso.Asignados {
+				grafo.Aristas[pcb.PID] = append(grafo.Aristas[pcb.PID], pid)
+			}
+		}
+	}
+	for pid, pcb := range vivos {
+		if pcb.EsperaHijo == sinEsperaHijo {
+			continue
+		}
+		grafo.Esperas[pid] = "HIJO " + describirObjetivoWait(pcb.EsperaHijo)
+		for _, hijo := range pcb.Hijos {
+			if pcb.EsperaHijo == esperaCualquierHijo || pcb.EsperaHijo == hijo {
+				grafo.Aristas[pid] = append(grafo.Aristas[pid], hijo)
+			}
+		}
+	}
+	for pid, pcb := range vivos {
+		if pcb.EsperaHilo == sinEsperaHilo {
+			continue
+		}
+		grafo.Esperas[pid] = fmt.Sprintf("HILO %d", pcb.EsperaHilo)
+		if hilo := buscarHilo(pcb.procesoPrincipal(), pcb.EsperaHilo); hilo != nil {
+			grafo.Aristas[pid] = append(grafo.Aristas[pid], hilo.PID)
+		}
+	}
+
+	terminados := map[int]bool{}
+	for avanzo := true; avanzo; {
+		avanzo = false
+		for pid, pcb := range vivos {
+			if terminados[pid] {
+				continue
+			}
+
+			puedeAvanzar := true
+			if nombre, espera := esperaRecurso[pid]; espera {
+				// Un recurso sin dueños se usa como señal: puede liberarlo cualquier proceso que siga avanzando
+				puedeAvanzar = disponibles[nombre] > 0 || (len(recursos[nombre].Asignados) == 0 && len(terminados) > 0)
+			} else if pcb.EsperaHijo != sinEsperaHijo {
+				// WAIT de un hijo: avanza cuando el hijo (o alguno, con ANY) puede terminar
+				puedeAvanzar = false
+				for _, hijo := range grafo.Aristas[pid] {
+					if terminados[hijo] || vivos[hijo] == nil {
+						puedeAvanzar = true
+						break
+					}
+				}
+			} else if pcb.EsperaHilo != sinEsperaHilo {
+				// THREAD_JOIN: avanza cuando el hilo esperado puede terminar (o si ya no existe)
+				puedeAvanzar = true
+				for _, hilo := range grafo.Aristas[pid] {
+					puedeAvanzar = terminados[hilo] || vivos[hilo] == nil
+				}
+			}
+			if !puedeAvanzar {
+				continue
+			}
+
+			terminados[pid] = true
+			for nombre, recurso := range recursos {
+				disponibles[nombre] += recurso.Asignados[pid]
+			}
+			avanzo = true
+		}
+	}
+
+	for pid := range vivos {
+		if !terminados[pid] {
+			grafo.Deadlock = append(grafo.Deadlock, pid)
+		}
+	}
+	sort.Ints(grafo.Deadlock)
+	grafo.Ciclo = buscarCiclo(grafo.Aristas, grafo.Deadlock)
+	return grafo
+}
+
+// buscarCiclo devuelve un ciclo del grafo entre los procesos indicados (el primero repetido al final), o nil
+func buscarCiclo(aristas map[int][]int, procesos []int) []int {
+	enDeadlock := map[int]bool{}
+	for _, pid := range procesos {
+		enDeadlock[pid] = true
+	}
+
+	const (
+		sinVisitar = iota
+		enCamino
+		visitado
+	)
+	estado := map[int]int{}
+	camino := []int{}
+
+	var visitar func(pid int) []int
+	visitar = func(pid int) []int {
+		estado[pid] = enCamino
+		camino = append(camino, pid)
+		vecinos := append([]int{}, aristas[pid]...)
+		sort.Ints(vecinos)
+		for _, vecino := range vecinos {
+			if !enDeadlock[vecino] {
+				continue
+			}
+			switch estado[vecino] {
+			case enCamino:
+				for i, p := range camino {
+					if p == vecino {
+						return append(append([]int{}, camino[i:]...), vecino)
+					}
+				}
+			case sinVisitar:
+				if ciclo := visitar(vecino); ciclo != nil {
+					return ciclo
+				}
+			}
+		}
+		camino = camino[:len(camino)-1]
+		estado[pid] = visitado
+		return nil
+	}
+
+	for _, pid := range procesos {
+		if estado[pid] == sinVisitar {
+			if ciclo := visitar(pid); ciclo != nil {
+				return ciclo
+			}
+		}
+	}
+	return nil
+}
+
+// verificarDeadlocks detecta deadlocks, los informa y aplica la política de recuperación hasta que no queden
+func verificarDeadlocks() GrafoEsperas {
+	deadlockMutex.Lock()
+	defer deadlockMutex.Unlock()
+
+	for {
+		grafo := construirGrafoEsperas()
+		if len(grafo.Deadlock) == 0 {
+			ultimoDeadlockReportado = ""
+			return grafo
+		}
+
+		// En modo periódico con REPORTAR el mismo deadlock se informa una sola vez
+		firma := fmt.Sprint(grafo.Deadlock)
+		politica := recuperacionDeadlock()
+		if firma != ultimoDeadlockReportado || politica != RecuperacionReportar {
+			informarDeadlock(grafo)
+			ultimoDeadlockReportado = firma
+		}
+		if politica == RecuperacionReportar {
+			return grafo
+		}
+
+		victima := elegirVictimaDeadlock(grafo.Deadlock, politica)
+		if victima == nil {
+			return grafo
+		}
+		utils.InfoLog.Warn(fmt.Sprintf("(%d) - Finalizado para recuperar deadlock", victima.PID), "politica", politica)
+		matarProceso(victima, "FINALIZADO_POR_DEADLOCK")
+	}
+}
+
+func informarDeadlock(grafo GrafoEsperas) {
+	detalle := make([]string, 0, len(grafo.Deadlock))
+	for _, pid := range grafo.Deadlock {
+		detalle = append(detalle, fmt.Sprintf("%d espera %s", pid, grafo.Esperas[pid]))
+	}
+
+	ciclo := make([]string, len(grafo.Ciclo))
+	for i, pid := range grafo.Ciclo {
+		ciclo[i] = fmt.Sprintf("%d", pid)
+	}
+	utils.InfoLog.Warn(fmt.Sprintf("Deadlock detectado - Procesos: %v", grafo.Deadlock),
+		"ciclo", strings.Join(ciclo, " -> "), "esperas", strings.Join(detalle, "; "))
+}
+
+// elegirVictimaDeadlock elige el proceso a finalizar según la política: el más joven, o el de menor
+// prioridad desempatando por el más joven
+func elegirVictimaDeadlock(procesos []int, politica string) *PCB {
+	var victima *PCB
+	for _, pid := range procesos {
+		pcb := BuscarPCBPorPID(pid)
+		if pcb == nil {
+			continue
+		}
+		if victima == nil {
+			victima = pcb
+			continue
+		}
+
+		if politica == RecuperacionMenorPrioridad && pcb.Prioridad != victima.Prioridad {
+			if pcb.Prioridad > victima.Prioridad {
+				victima = pcb
+			}
+			continue
+		}
+		if pcb.HoraCreacion.After(victima.HoraCreacion) ||
+			(pcb.HoraCreacion.Equal(victima.HoraCreacion) && pcb.PID > victima.PID) {
+			victima = pcb
+		}
+	}
+	return victima
+}
+
+// iniciarDeteccionPeriodica lanza la verificación periódica si el modo es PERIODICA y no estaba en marcha.
+// La rutina termina cuando se recarga otro modo
+func iniciarDeteccionPeriodica() {
+	if deteccionDeadlock() != DeteccionPeriodica {
+		return
+	}
+
+	deteccionPeriodicaMutex.Lock()
+	defer deteccionPeriodicaMutex.Unlock()
+	if deteccionPeriodicaActiva {
+		return
+	}
+	deteccionPeriodicaActiva = true
+
+	go func() {
+		utils.InfoLog.Info("Iniciando detección periódica de deadlocks", "intervalo_ms", intervaloDeadlock().Milliseconds())
+		for {
+			utils.Dormir(intervaloDeadlock())
+			if deteccionDeadlock() != DeteccionPeriodica {
+				deteccionPeriodicaMutex.Lock()
+				deteccionPeriodicaActiva = false
+				deteccionPeriodicaMutex.Unlock()
+				utils.InfoLog.Info("Detección periódica de deadlocks detenida")
+				return
+			}
+			verificarDeadlocks()
+		}
+	}()
+}
+
+func intervaloDeadlock() time.Duration {
+	intervalo := configKernel().DeadlockInterval
+	if intervalo <= 0 {
+		intervalo = intervaloDeadlockPorDefecto
+	}
+	return time.Duration(intervalo) * time.Millisecond
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+// Cada hilo es un PCB planificable con su propio PC (y su propio identificador en mapaPCBs) que comparte la
+// memoria del proceso: Memoria lo conoce por el PID del hilo principal y el TID. El hilo principal (TID 0)
+// es el PCB creado por INIT_PROC o desde la consola; los secundarios los crea THREAD_CREATE, entran
+// directo a READY y no ocupan grado de multiprogramación
+
+const (
+	tidPrincipal  = 0
+	sinEsperaHilo = -1 // El hilo no está bloqueado en THREAD_JOIN
+
+	motivoFinDelProceso = "FIN_DEL_PROCESO" // Motivo de los hilos secundarios que finalizan junto con su proceso
+)
+
+// hilosMutex protege Hilos, ProximoTID, EsperaHilo y hilosCerrados de todos los PCBs
+var hilosMutex sync.Mutex
+
+// procesoPrincipal devuelve el hilo principal del proceso al que pertenece el PCB
+func (pcb *PCB) procesoPrincipal() *PCB {
+	if pcb.Proceso != nil {
+		return pcb.Proceso
+	}
+	return pcb
+}
+
+// PIDMemoria devuelve el PID con el que Memoria conoce al proceso (compartido por todos sus hilos)
+func (pcb *PCB) PIDMemoria() int {
+	return pcb.procesoPrincipal().PID
+}
+
+func (pcb *PCB) esHiloSecundario() bool {
+	return pcb.Proceso != nil
+}
+
+// crearHilo ejecuta THREAD_CREATE: carga el script del hilo en Memoria y lo pasa directo a READY.
+// Sin prioridad explícita (negativa) hereda la prioridad base del hilo que lo crea
+func crearHilo(creador *PCB, archivo string, prioridad int) (*PCB, error) {
+	principal := creador.procesoPrincipal()
+
+	hilosMutex.Lock()
+	if principal.hilosCerrados {
+		hilosMutex.Unlock()
+		return nil, fmt.Errorf("el proceso %d está finalizando", principal.PID)
+	}
+	tid := principal.ProximoTID
+	principal.ProximoTID++
+	hilosMutex.Unlock()
+
+	if err := crearHiloEnMemoria(principal.PID, tid, archivo); err != nil {
+		return nil, err
+	}
+
+	hilo := NuevoPCB(-1, 0)
+	hilo.NombreArchivo = archivo
+	hilo.TID = tid
+	hilo.Proceso = principal
+	hilo.Tickets = creador.Tickets
+	if prioridad < 0 {
+		prioridad = creador.PrioridadBase
+	}
+	hilo.AsignarPrioridad(prioridad)
+
+	hilosMutex.Lock()
+	if principal.hilosCerrados {
+		// El proceso empezó a finalizar mientras Memoria cargaba el hilo
+		hilosMutex.Unlock()
+		FinalizarProceso(hilo, motivoFinDelProceso)
+		return nil, fmt.Errorf("el proceso %d está finalizando", principal.PID)
+	}
+	principal.Hilos = append(principal.Hilos, hilo)
+	hilosMutex.Unlock()
+
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - Se crea el hilo %d del proceso %d", hilo.PID, tid, principal.PID))
+	MoverProcesoAReady(hilo)
+	go despacharProcesoSiCorresponde()
+	return hilo, nil
+}
+
+// esperarHilo ejecuta THREAD_JOIN. Si el hilo esperado no existe o ya terminó el que llama sigue
+// ejecutando; si no, se bloquea hasta que termine. Devuelve si se bloqueó
+func esperarHilo(pcb *PCB, tid int) bool {
+	hilosMutex.Lock()
+	defer hilosMutex.Unlock()
+
+	objetivo := buscarHilo(pcb.procesoPrincipal(), tid)
+	if objetivo == nil || objetivo == pcb || objetivo.Estado == EstadoExit {
+		utils.InfoLog.Warn("THREAD_JOIN sin hilo que esperar", "pid", pcb.PID, "proceso", pcb.PIDMemoria(), "tid", tid)
+		return false
+	}
+
+	// Se bloquea con hilosMutex tomado para que la finalización del hilo lo encuentre ya en BLOCKED
+	pcb.EsperaHilo = tid
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - Bloqueado por THREAD_JOIN: TID %d", pcb.PID, tid))
+	MoverProcesoABlocked(pcb, "THREAD_JOIN")
+	if deteccionDeadlock() == DeteccionAlBloquear {
+		go verificarDeadlocks()
+	}
+	return true
+}
+
+// buscarHilo devuelve el hilo vivo del proceso con ese TID, o nil. Requiere hilosMutex tomado
+func buscarHilo(principal *PCB, tid int) *PCB {
+	if tid == tidPrincipal {
+		return principal
+	}
+	for _, hilo := range principal.Hilos {
+		if hilo.TID == tid {
+			return hilo
+		}
+	}
+	return nil
+}
+
+// notificarFinHilo se llama al finalizar un PCB: lo saca de los hilos del proceso y despierta a los
+// hilos que lo esperaban en THREAD_JOIN
+func notificarFinHilo(pcb *PCB) {
+	principal := pcb.procesoPrincipal()
+
+	hilosMutex.Lock()
+	if pcb != principal {
+		removerDeCola(&principal.Hilos, pcb)
+	}
+	pcb.EsperaHilo = sinEsperaHilo
+
+	// Si el proceso está finalizando no se despierta a nadie: todos sus hilos terminan con él
+	aDespertar := []*PCB{}
+	if !principal.hilosCerrados {
+		for _, hilo := range append([]*PCB{principal}, principal.Hilos...) {
+			if hilo != pcb && hilo.Estado != EstadoExit && hilo.EsperaHilo == pcb.TID {
+				hilo.EsperaHilo = sinEsperaHilo
+				aDespertar = append(aDespertar, hilo)
+			}
+		}
+	}
+	hilosMutex.Unlock()
+
+	for _, hilo := range aDespertar {
+		desbloquearProceso(hilo, fmt.Sprintf("THREAD_JOIN del TID %d", pcb.TID))
+	}
+}
+
+// finalizarHilosDelProceso finaliza todos los hilos secundarios cuando termina el hilo principal.
+// Después de esto el proceso ya no admite THREAD_CREATE
+func finalizarHilosDelProceso(principal *PCB) {
+	hilosMutex.Lock()
+	principal.hilosCerrados = true
+	hilos := append([]*PCB{}, principal.Hilos...)
+	hilosMutex.Unlock()
+
+	for _, hilo := range hilos {
+		utils.InfoLog.Info("Finalizando hilo junto con su proceso", "pid", hilo.PID, "proceso", principal.PID, "tid", hilo.TID)
+		matarProceso(hilo, motivoFinDelProceso)
+	}
+}
+
+// tieneHilosVivos indica si el proceso tiene hilos secundarios. Mientras los tenga no se suspende,
+// porque SWAP bajaría la memoria que los otros hilos siguen usando
+func tieneHilosVivos(pcb *PCB) bool {
+	hilosMutex.Lock()
+	defer hilosMutex.Unlock()
+	return len(pcb.Hilos) > 0
+}
+
+// hilosProceso devuelve una copia de los identificadores de los hilos secundarios y del THREAD_JOIN pendiente
+func hilosProceso(pcb *PCB) (hilos []int, esperando int) {
+	hilosMutex.Lock()
+	defer hilosMutex.Unlock()
+
+	hilos = []int{}
+	for _, hilo := range pcb.Hilos {
+		hilos = append(hilos, hilo.PID)
+	}
+	return hilos, pcb.EsperaHilo
+}
+
+func crearHiloEnMemoria(pid, tid int, archivo string) error {
+	cliente := GetMemoriaClient()
+	if cliente == nil {
+		return fmt.Errorf("no hay cliente de memoria")
+	}
+
+	datos := map[string]interface{}{
+		"pid":     pid,
+		"tid":     tid,
+		"archivo": archivo,
+	}
+
+	respuesta, err := cliente.EnviarHTTPMensaje(utils.MensajeCrearHilo, "default", datos)
+	if err != nil {
+		return fmt.Errorf("error de comunicación con Memoria: %w", err)
+	}
+	if respuestaMap, ok := respuesta.(map[string]interface{}); ok {
+		if status, _ := respuestaMap["status"].(string); status == "OK" {
+			return nil
+		}
+		if mensaje, hayError := respuestaMap["error"].(string); hayError {
+			return fmt.Errorf("memoria rechazó el hilo: %s", mensaje)
+		}
+	}
+	return fmt.Errorf("respuesta de Memoria en formato inválido")
+}
+
+func notificarFinHiloAMemoria(pid, tid int) {
+	cliente := GetMemoriaClient()
+	if cliente == nil {
+		utils.ErrorLog.Error("No se pudo obtener cliente de memoria para finalizar hilo", "pid", pid, "tid", tid)
+		return
+	}
+
+	datos := map[string]interface{}{
+		"pid": pid,
+		"tid": tid,
+	}
+
+	if _, err := cliente.EnviarHTTPMensaje(utils.MensajeFinalizarHilo, "default", datos); err != nil {
+		utils.ErrorLog.Error("Error notificando finalización de hilo a Memoria", "pid", pid, "tid", tid, "error", err.Error())
+	}
+}
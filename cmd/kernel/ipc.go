@@ -0,0 +1,410 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+const (
+	TipoIPCCola = "COLA" // Mensajes completos; la capacidad es la cantidad de mensajes
+	TipoIPCPipe = "PIPE" // Flujo de bytes; la capacidad es la cantidad de bytes
+)
+
+// ObjetoIPC es una cola de mensajes o un pipe con nombre administrado por el kernel. Los datos se copian
+// desde y hacia la memoria de cada proceso a través de Memoria
+type ObjetoIPC struct {
+	Nombre     string
+	Tipo       string
+	Capacidad  int
+	Mensajes   []string        // COLA: mensajes encolados, en orden de llegada
+	Buffer     string          // PIPE: bytes escritos que todavía no se leyeron
+	Abiertos   map[int]bool    // PIDs (de Memoria) de los procesos que lo abrieron
+	Emisores   []*operacionIPC // Bloqueados porque no había lugar
+	Receptores []*operacionIPC // Bloqueados porque no había datos
+}
+
+// operacionIPC es un envío o una recepción pendiente de un proceso bloqueado
+type operacionIPC struct {
+	pcb       *PCB
+	direccion int    // Dirección lógica donde se deja lo recibido
+	tamanio   int    // Máximo a recibir
+	datos     string // Lo que se quiere enviar, ya leído de la memoria del emisor
+}
+
+// entregaIPC son datos recibidos por un proceso bloqueado, que se escriben en su memoria al volver a ejecutar
+type entregaIPC struct {
+	objeto    string
+	direccion int
+	datos     string
+}
+
+var (
+	objetosIPC = make(map[string]*ObjetoIPC)
+	ipcMutex   sync.Mutex
+)
+
+// crearIPC crea el objeto si no existe y lo abre para el proceso. Abrir uno existente del mismo tipo no es un error
+func crearIPC(pcb *PCB, nombre, tipo string, capacidad int) error {
+	tipo = strings.ToUpper(tipo)
+	if tipo != TipoIPCCola && tipo != TipoIPCPipe {
+		return fmt.Errorf("tipo de IPC inválido: %s", tipo)
+	}
+	if nombre == "" || capacidad <= 0 {
+		return fmt.Errorf("nombre o capacidad inválidos: %q %d", nombre, capacidad)
+	}
+
+	ipcMutex.Lock()
+	defer ipcMutex.Unlock()
+
+	objeto, existe := objetosIPC[nombre]
+	if existe && objeto.Tipo != tipo {
+		return fmt.Errorf("ya existe %s como %s", nombre, objeto.Tipo)
+	}
+	if !existe {
+		objeto = &ObjetoIPC{Nombre: nombre, Tipo: tipo, Capacidad: capacidad, Abiertos: make(map[int]bool)}
+		objetosIPC[nombre] = objeto
+		utils.InfoLog.Info("Objeto IPC creado", "nombre", nombre, "tipo", tipo, "capacidad", capacidad)
+	}
+	objeto.Abiertos[pcb.PIDMemoria()] = true
+	return nil
+}
+
+// abrirIPC abre un objeto existente para el proceso
+func abrirIPC(pcb *PCB, nombre string) error {
+	ipcMutex.Lock()
+	defer ipcMutex.Unlock()
+
+	objeto, existe := objetosIPC[nombre]
+	if !existe {
+		return fmt.Errorf("no existe el objeto IPC %s", nombre)
+	}
+	objeto.Abiertos[pcb.PIDMemoria()] = true
+	return nil
+}
+
+// cerrarIPC cierra el objeto para el proceso; al cerrarlo el último se destruye
+func cerrarIPC(pcb *PCB, nombre string) error {
+	ipcMutex.Lock()
+	defer ipcMutex.Unlock()
+
+	objeto, existe := objetosIPC[nombre]
+	if !existe || !objeto.Abiertos[pcb.PIDMemoria()] {
+		return fmt.Errorf("el proceso no tiene abierto %s", nombre)
+	}
+	delete(objeto.Abiertos, pcb.PIDMemoria())
+	destruirIPCSinUso(objeto)
+	return nil
+}
+
+// destruirIPCSinUso elimina el objeto si ya nadie lo tiene abierto. Requiere ipcMutex tomado
+func destruirIPCSinUso(objeto *ObjetoIPC) {
+	if len(objeto.Abiertos) > 0 {
+		return
+	}
+	delete(objetosIPC, objeto.Nombre)
+	utils.InfoLog.Info("Objeto IPC destruido", "nombre", objeto.Nombre, "mensajes_descartados", len(objeto.Mensajes), "bytes_descartados", len(objeto.Buffer))
+}
+
+// objetoAbierto devuelve el objeto si existe y el proceso lo tiene abierto. Requiere ipcMutex tomado
+func objetoAbierto(pcb *PCB, nombre string) (*ObjetoIPC, error) {
+	objeto, existe := objetosIPC[nombre]
+	if !existe {
+		return nil, fmt.Errorf("no existe el objeto IPC %s", nombre)
+	}
+	if !objeto.Abiertos[pcb.PIDMemoria()] {
+		return nil, fmt.Errorf("el proceso no tiene abierto %s", nombre)
+	}
+	return objeto, nil
+}
+
+// enviarIPC ejecuta IPC_SEND: lee el mensaje de la memoria del proceso y lo deja en el objeto. Si no hay
+// lugar, el proceso se bloquea con el mensaje ya copiado hasta que un receptor libere espacio
+func enviarIPC(pcb *PCB, nombre string, direccion, tamanio int) error {
+	if tamanio <= 0 {
+		return fmt.Errorf("tamaño de envío inválido: %d", tamanio)
+	}
+	datos, err := leerMemoriaProceso(pcb, direccion, tamanio)
+	if err != nil {
+		return err
+	}
+
+	ipcMutex.Lock()
+	objeto, err := objetoAbierto(pcb, nombre)
+	if err != nil {
+		ipcMutex.Unlock()
+		return err
+	}
+	if objeto.Tipo == TipoIPCPipe && len(datos) > objeto.Capacidad {
+		ipcMutex.Unlock()
+		return fmt.Errorf("el envío de %d bytes supera la capacidad del pipe %s (%d)", len(datos), nombre, objeto.Capacidad)
+	}
+
+	envio := &operacionIPC{pcb: pcb, datos: datos}
+	if len(objeto.Emisores) > 0 || !objeto.hayLugar(len(datos)) {
+		// Se bloquea con ipcMutex tomado para que el receptor que libere lugar lo encuentre ya en BLOCKED
+		objeto.Emisores = append(objeto.Emisores, envio)
+		utils.InfoLog.Info(fmt.Sprintf("(%d) - Bloqueado por IPC: %s lleno", pcb.PID, nombre))
+		MoverProcesoABlocked(pcb, "IPC_SEND_"+nombre)
+		ipcMutex.Unlock()
+		return nil
+	}
+
+	objeto.depositar(datos)
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - Envía %d bytes por %s", pcb.PID, len(datos), nombre))
+	despertados := objeto.atenderPendientes()
+	ipcMutex.Unlock()
+
+	despertarIPC(despertados, nombre)
+	return nil
+}
+
+// recibirIPC ejecuta IPC_RECEIVE: toma un mensaje (o hasta tamanio bytes del pipe) y lo escribe en la
+// memoria del proceso. Si no hay datos, el proceso se bloquea y lo recibido se escribe al volver a ejecutar
+func recibirIPC(pcb *PCB, nombre string, direccion, tamanio int) error {
+	if tamanio <= 0 {
+		return fmt.Errorf("tamaño de recepción inválido: %d", tamanio)
+	}
+
+	ipcMutex.Lock()
+	objeto, err := objetoAbierto(pcb, nombre)
+	if err != nil {
+		ipcMutex.Unlock()
+		return err
+	}
+
+	recepcion := &operacionIPC{pcb: pcb, direccion: direccion, tamanio: tamanio}
+	if len(objeto.Receptores) > 0 || !objeto.hayDatos() {
+		objeto.Receptores = append(objeto.Receptores, recepcion)
+		utils.InfoLog.Info(fmt.Sprintf("(%d) - Bloqueado por IPC: %s vacío", pcb.PID, nombre))
+		MoverProcesoABlocked(pcb, "IPC_RECEIVE_"+nombre)
+		ipcMutex.Unlock()
+		return nil
+	}
+
+	datos := objeto.extraer(tamanio)
+	despertados := objeto.atenderPendientes()
+	ipcMutex.Unlock()
+
+	despertarIPC(despertados, nombre)
+	if err := escribirMemoriaProceso(pcb, direccion, datos); err != nil {
+		return err
+	}
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - Recibe %d bytes por %s", pcb.PID, len(datos), nombre))
+	return nil
+}
+
+func (objeto *ObjetoIPC) hayLugar(tamanio int) bool {
+	if objeto.Tipo == TipoIPCCola {
+		return len(objeto.Mensajes) < objeto.Capacidad
+	}
+	return len(objeto.Buffer)+tamanio <= objeto.Capacidad
+}
+
+func (objeto *ObjetoIPC) hayDatos() bool {
+	return len(objeto.Mensajes) > 0 || len(objeto.Buffer) > 0
+}
+
+func (objeto *ObjetoIPC) depositar(datos string) {
+	if objeto.Tipo == TipoIPCCola {
+		objeto.Mensajes = append(objeto.Mensajes, datos)
+		return
+	}
+	objeto.Buffer += datos
+}
+
+// extraer saca el próximo mensaje (truncado a tamanio) o hasta tamanio bytes del pipe
+func (objeto *ObjetoIPC) extraer(tamanio int) string {
+	if objeto.Tipo == TipoIPCCola {
+		mensaje := objeto.Mensajes[0]
+		objeto.Mensajes = objeto.Mensajes[1:]
+		if len(mensaje) > tamanio {
+			utils.InfoLog.Warn("Mensaje truncado al recibir", "objeto", objeto.Nombre, "tamanio_mensaje", len(mensaje), "tamanio_recepcion", tamanio)
+			mensaje = mensaje[:tamanio]
+		}
+		return mensaje
+	}
+	cantidad := min(tamanio, len(objeto.Buffer))
+	datos := objeto.Buffer[:cantidad]
+	objeto.Buffer = objeto.Buffer[cantidad:]
+	return datos
+}
+
+// atenderPendientes completa, en orden de llegada, los envíos y recepciones bloqueados que ya pueden
+// avanzar y devuelve los procesos a despertar. Requiere ipcMutex tomado
+func (objeto *ObjetoIPC) atenderPendientes() []*PCB {
+	despertados := []*PCB{}
+	for avanzo := true; avanzo; {
+		avanzo = false
+		if len(objeto.Emisores) > 0 && objeto.hayLugar(len(objeto.Emisores[0].datos)) {
+			envio := objeto.Emisores[0]
+			objeto.Emisores = objeto.Emisores[1:]
+			objeto.depositar(envio.datos)
+			utils.InfoLog.Info(fmt.Sprintf("(%d) - Envía %d bytes por %s al ser desbloqueado", envio.pcb.PID, len(envio.datos), objeto.Nombre))
+			despertados = append(despertados, envio.pcb)
+			avanzo = true
+		}
+		if len(objeto.Receptores) > 0 && objeto.hayDatos() {
+			recepcion := objeto.Receptores[0]
+			objeto.Receptores = objeto.Receptores[1:]
+			recepcion.pcb.entregaIPC = &entregaIPC{objeto: objeto.Nombre, direccion: recepcion.direccion, datos: objeto.extraer(recepcion.tamanio)}
+			despertados = append(despertados, recepcion.pcb)
+			avanzo = true
+		}
+	}
+	return despertados
+}
+
+func despertarIPC(despertados []*PCB, nombre string) {
+	for _, pcb := range despertados {
+		desbloquearProceso(pcb, "IPC "+nombre)
+	}
+}
+
+// completarEntregaIPC escribe en la memoria del proceso lo que recibió mientras estaba bloqueado. Se llama
+// al despacharlo, cuando su memoria seguro está cargada. Devuelve false si no se pudo escribir
+func completarEntregaIPC(pcb *PCB) bool {
+	entrega := pcb.entregaIPC
+	if entrega == nil {
+		return true
+	}
+	pcb.entregaIPC = nil
+
+	if err := escribirMemoriaProceso(pcb, entrega.direccion, entrega.datos); err != nil {
+		utils.ErrorLog.Error("No se pudo entregar lo recibido por IPC", "pid", pcb.PID, "objeto", entrega.objeto, "error", err)
+		return false
+	}
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - Recibe %d bytes por %s", pcb.PID, len(entrega.datos), entrega.objeto))
+	return true
+}
+
+// liberarIPCDeProceso saca al PCB que finaliza de las esperas y, si es el hilo principal, cierra los
+// objetos que el proceso tenía abiertos
+func liberarIPCDeProceso(pcb *PCB) {
+	ipcMutex.Lock()
+	defer ipcMutex.Unlock()
+
+	for _, objeto := range objetosIPC {
+		objeto.Emisores = quitarOperacionesDe(objeto.Emisores, pcb)
+		objeto.Receptores = quitarOperacionesDe(objeto.Receptores, pcb)
+		if !pcb.esHiloSecundario() && objeto.Abiertos[pcb.PID] {
+			delete(objeto.Abiertos, pcb.PID)
+			destruirIPCSinUso(objeto)
+		}
+	}
+}
+
+func quitarOperacionesDe(operaciones []*operacionIPC, pcb *PCB) []*operacionIPC {
+	restantes := operaciones[:0]
+	for _, operacion := range operaciones {
+		if operacion.pcb != pcb {
+			restantes = append(restantes, operacion)
+		}
+	}
+	return restantes
+}
+
+// leerMemoriaProceso lee bytes de la memoria del proceso a partir de una dirección lógica
+func leerMemoriaProceso(pcb *PCB, direccion, tamanio int) (string, error) {
+	cliente := GetMemoriaClient()
+	if cliente == nil {
+		return "", fmt.Errorf("no hay cliente de memoria")
+	}
+
+	datos := map[string]interface{}{
+		"pid":              pcb.PIDMemoria(),
+		"direccion_logica": direccion,
+		"tamanio":          tamanio,
+	}
+	respuesta, err := cliente.EnviarHTTPMensaje(utils.MensajeLeer, "default", datos)
+	if err != nil {
+		return "", fmt.Errorf("error leyendo memoria del proceso %d: %w", pcb.PIDMemoria(), err)
+	}
+	respuestaMap, _ := respuesta.(map[string]interface{})
+	if mensaje, hayError := respuestaMap["error"].(string); hayError {
+		return "", fmt.Errorf("memoria rechazó la lectura: %s", mensaje)
+	}
+	valor, ok := respuestaMap["valor"].(string)
+	if !ok {
+		return "", fmt.Errorf("respuesta de lectura en formato inválido")
+	}
+	return valor, nil
+}
+
+// escribirMemoriaProceso escribe bytes en la memoria del proceso a partir de una dirección lógica
+func escribirMemoriaProceso(pcb *PCB, direccion int, valor string) error {
+	cliente := GetMemoriaClient()
+	if cliente == nil {
+		return fmt.Errorf("no hay cliente de memoria")
+	}
+
+	datos := map[string]interface{}{
+		"pid":              pcb.PIDMemoria(),
+		"direccion_logica": direccion,
+		"valor":            valor,
+	}
+	respuesta, err := cliente.EnviarHTTPMensaje(utils.MensajeEscribir, "default", datos)
+	if err != nil {
+		return fmt.Errorf("error escribiendo memoria del proceso %d: %w", pcb.PIDMemoria(), err)
+	}
+	respuestaMap, _ := respuesta.(map[string]interface{})
+	if mensaje, hayError := respuestaMap["error"].(string); hayError {
+		return fmt.Errorf("memoria rechazó la escritura: %s", mensaje)
+	}
+	return nil
+}
+
+// estadoIPC es la vista de un objeto IPC para la consola y la API de administración
+type estadoIPC struct {
+	Nombre     string `json:"nombre"`
+	Tipo       string `json:"tipo"`
+	Capacidad  int    `json:"capacidad"`
+	Mensajes   int    `json:"mensajes"`
+	Bytes      int    `json:"bytes"`
+	Abiertos   []int  `json:"abiertos"`
+	Emisores   []int  `json:"emisores_bloqueados"`
+	Receptores []int  `json:"receptores_bloqueados"`
+}
+
+// estadoObjetosIPC devuelve una copia del estado de todos los objetos IPC, ordenados por nombre
+func estadoObjetosIPC() []estadoIPC {
+	ipcMutex.Lock()
+	defer ipcMutex.Unlock()
+
+	estados := make([]estadoIPC, 0, len(objetosIPC))
+	for _, objeto := range objetosIPC {
+		abiertos := []int{}
+		for pid := range objeto.Abiertos {
+			abiertos = append(abiertos, pid)
+		}
+		sort.Ints(abiertos)
+
+		bytes := len(objeto.Buffer)
+		for _, mensaje := range objeto.Mensajes {
+			bytes += len(mensaje)
+		}
+		estados = append(estados, estadoIPC{
+			Nombre:     objeto.Nombre,
+			Tipo:       objeto.Tipo,
+			Capacidad:  objeto.Capacidad,
+			Mensajes:   len(objeto.Mensajes),
+			Bytes:      bytes,
+			Abiertos:   abiertos,
+			Emisores:   pidsDeOperaciones(objeto.Emisores),
+			Receptores: pidsDeOperaciones(objeto.Receptores),
+		})
+	}
+	sort.Slice(estados, func(i, j int) bool { return estados[i].Nombre < estados[j].Nombre })
+	return estados
+}
+
+func pidsDeOperaciones(operaciones []*operacionIPC) []int {
+	pids := make([]int, 0, len(operaciones))
+	for _, operacion := range operaciones {
+		pids = append(pids, operacion.pcb.PID)
+	}
+	return pids
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+const (
+	sinPadre            = -1 // Proceso creado por el kernel (consola, API o proceso inicial)
+	pidAdoptivo         = 0  // Los huérfanos pasan a ser hijos del proceso inicial, como init en Unix
+	esperaCualquierHijo = -1 // WAIT ANY
+	sinEsperaHijo       = -2 // El proceso no está bloqueado en WAIT
+
+	// Estados de salida que recibe el padre en WAIT
+	codigoSalidaNormal     = 0  // EXIT
+	codigoSalidaError      = 1  // Error de ejecución, de IO o de memoria
+	codigoSalidaFinalizado = 2  // Finalizado desde la consola, la API de administración o para recuperar un deadlock
+	codigoSalidaSinHijos   = -1 // WAIT sin hijos que esperar
+)
+
+// salidaHijo es el estado de un hijo terminado que todavía no recolectó un WAIT del padre
+type salidaHijo struct {
+	PID    int
+	Estado int
+	Motivo string
+}
+
+// jerarquiaMutex protege PadrePID, Hijos, hijosFinalizados y EsperaHijo de todos los PCBs
+var jerarquiaMutex sync.Mutex
+
+// codigoSalida traduce el motivo de finalización al estado que recibe el padre
+func codigoSalida(motivo string) int {
+	switch {
+	case motivo == "EXIT":
+		return codigoSalidaNormal
+	case strings.HasPrefix(motivo, "FINALIZADO_POR_"):
+		return codigoSalidaFinalizado
+	default:
+		return codigoSalidaError
+	}
+}
+
+// registrarHijo vincula el proceso creado por INIT_PROC con su creador
+func registrarHijo(padre, hijo *PCB) {
+	jerarquiaMutex.Lock()
+	defer jerarquiaMutex.Unlock()
+
+	hijo.PadrePID = padre.PID
+	padre.Hijos = append(padre.Hijos, hijo.PID)
+	utils.InfoLog.Info("Proceso hijo registrado", "padre", padre.PID, "hijo", hijo.PID)
+}
+
+// esperarHijo ejecuta WAIT para un proceso en EXEC. Si el hijo ya terminó (o no hay hijos que esperar)
+// entrega el estado y el proceso sigue ejecutando; si no, lo bloquea hasta que termine. Devuelve si se bloqueó
+func esperarHijo(pcb *PCB, objetivo int) bool {
+	jerarquiaMutex.Lock()
+	defer jerarquiaMutex.Unlock()
+
+	if salida, ok := tomarHijoFinalizado(pcb, objetivo); ok {
+		entregarSalidaHijo(pcb, salida)
+		return false
+	}
+
+	if !tieneHijoVivo(pcb, objetivo) {
+		utils.InfoLog.Warn("WAIT sin hijos que esperar", "pid", pcb.PID, "objetivo", describirObjetivoWait(objetivo))
+		entregarSalidaHijo(pcb, salidaHijo{PID: -1, Estado: codigoSalidaSinHijos})
+		return false
+	}
+
+	// Se bloquea con jerarquiaMutex tomado para que la finalización del hijo lo encuentre ya en BLOCKED
+	pcb.EsperaHijo = objetivo
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - Bloqueado por WAIT: %s", pcb.PID, describirObjetivoWait(objetivo)))
+	MoverProcesoABlocked(pcb, "WAIT")
+	if deteccionDeadlock() == DeteccionAlBloquear {
+		go verificarDeadlocks()
+	}
+	return true
+}
+
+// tomarHijoFinalizado quita y devuelve el estado pendiente del hijo esperado. Requiere jerarquiaMutex tomado
+func tomarHijoFinalizado(pcb *PCB, objetivo int) (salidaHijo, bool) {
+	for i, salida := range pcb.hijosFinalizados {
+		if objetivo == esperaCualquierHijo || salida.PID == objetivo {
+			pcb.hijosFinalizados = append(pcb.hijosFinalizados[:i], pcb.hijosFinalizados[i+1:]...)
+			return salida, true
+		}
+	}
+	return salidaHijo{}, false
+}
+
+// tieneHijoVivo indica si el proceso tiene algún hijo vivo que coincida con el objetivo. Requiere jerarquiaMutex tomado
+func tieneHijoVivo(pcb *PCB, objetivo int) bool {
+	for _, hijo := range pcb.Hijos {
+		if objetivo == esperaCualquierHijo || hijo == objetivo {
+			return true
+		}
+	}
+	return false
+}
+
+// entregarSalidaHijo deja en el padre el PID y el estado del hijo recolectado. Requiere jerarquiaMutex tomado
+func entregarSalidaHijo(padre *PCB, salida salidaHijo) {
+	padre.ultimoWait = &salida
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - WAIT recolectó al hijo %d con estado %d", padre.PID, salida.PID, salida.Estado))
+}
+
+// notificarFinAlPadre se llama al finalizar un proceso: reparenta a sus hijos vivos, descarta sus hijos
+// no recolectados y entrega su estado al padre, despertándolo si lo estaba esperando
+func notificarFinAlPadre(pcb *PCB) {
+	jerarquiaMutex.Lock()
+
+	huerfanos := pcb.Hijos
+	pcb.Hijos = nil
+	pcb.hijosFinalizados = nil
+	pcb.EsperaHijo = sinEsperaHijo
+
+	adoptivo := BuscarPCBPorPID(pidAdoptivo)
+	if adoptivo == pcb || (adoptivo != nil && adoptivo.Estado == EstadoExit) {
+		adoptivo = nil
+	}
+	for _, pidHijo := range huerfanos {
+		hijo := BuscarPCBPorPID(pidHijo)
+		if hijo == nil {
+			continue
+		}
+		hijo.PadrePID = sinPadre
+		if adoptivo != nil {
+			hijo.PadrePID = adoptivo.PID
+			adoptivo.Hijos = append(adoptivo.Hijos, hijo.PID)
+		}
+		utils.InfoLog.Info("Proceso huérfano reparentado", "pid", hijo.PID, "padre_anterior", pcb.PID, "nuevo_padre", hijo.PadrePID)
+	}
+
+	var padreADespertar *PCB
+	if padre := BuscarPCBPorPID(pcb.PadrePID); padre != nil && padre.Estado != EstadoExit {
+		for i, hijo := range padre.Hijos {
+			if hijo == pcb.PID {
+				padre.Hijos = append(padre.Hijos[:i], padre.Hijos[i+1:]...)
+				break
+			}
+		}
+
+		salida := salidaHijo{PID: pcb.PID, Estado: codigoSalida(pcb.MotivoFinalizacion), Motivo: pcb.MotivoFinalizacion}
+		if padre.EsperaHijo == esperaCualquierHijo || padre.EsperaHijo == pcb.PID {
+			padre.EsperaHijo = sinEsperaHijo
+			entregarSalidaHijo(padre, salida)
+			padreADespertar = padre
+		} else {
+			padre.hijosFinalizados = append(padre.hijosFinalizados, salida)
+		}
+	}
+
+	jerarquiaMutex.Unlock()
+
+	if padreADespertar != nil {
+		desbloquearProceso(padreADespertar, "WAIT")
+	}
+}
+
+// descendientesDe devuelve los PIDs del subárbol del proceso (sin incluirlo), de la raíz hacia las hojas
+func descendientesDe(pcb *PCB) []int {
+	jerarquiaMutex.Lock()
+	defer jerarquiaMutex.Unlock()
+
+	descendientes := []int{}
+	pendientes := append([]int{}, pcb.Hijos...)
+	for len(pendientes) > 0 {
+		pid := pendientes[0]
+		pendientes = pendientes[1:]
+		descendientes = append(descendientes, pid)
+		if hijo := BuscarPCBPorPID(pid); hijo != nil {
+			pendientes = append(pendientes, hijo.Hijos...)
+		}
+	}
+	return descendientes
+}
+
+// matarArbolProcesos finaliza el proceso y todo su subárbol, de las hojas hacia la raíz para que
+// ningún descendiente quede huérfano en el camino. Devuelve los PIDs finalizados
+func matarArbolProcesos(pcb *PCB, motivo string) []int {
+	descendientes := descendientesDe(pcb)
+	finalizados := []int{}
+	for i := len(descendientes) - 1; i >= 0; i-- {
+		if descendiente := BuscarPCBPorPID(descendientes[i]); descendiente != nil {
+			matarProceso(descendiente, motivo)
+			finalizados = append(finalizados, descendiente.PID)
+		}
+	}
+	matarProceso(pcb, motivo)
+	return append(finalizados, pcb.PID)
+}
+
+// relacionesProceso devuelve una copia del padre, los hijos vivos y el WAIT pendiente del proceso
+func relacionesProceso(pcb *PCB) (padre int, hijos []int, esperando string) {
+	jerarquiaMutex.Lock()
+	defer jerarquiaMutex.Unlock()
+
+	hijos = append([]int{}, pcb.Hijos...)
+	if pcb.EsperaHijo != sinEsperaHijo {
+		esperando = describirObjetivoWait(pcb.EsperaHijo)
+	}
+	return pcb.PadrePID, hijos, esperando
+}
+
+// resultadoUltimoWait devuelve el hijo entregado por el último WAIT del proceso, si hizo alguno
+func resultadoUltimoWait(pcb *PCB) (salidaHijo, bool) {
+	jerarquiaMutex.Lock()
+	defer jerarquiaMutex.Unlock()
+
+	if pcb.ultimoWait == nil {
+		return salidaHijo{}, false
+	}
+	return *pcb.ultimoWait, true
+}
+
+func describirObjetivoWait(objetivo int) string {
+	if objetivo == esperaCualquierHijo {
+		return "ANY"
+	}
+	return fmt.Sprintf("%d", objetivo)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
@@ -9,48 +10,87 @@ import (
 
 // KernelConfig define la configuración del módulo Kernel
 type KernelConfig struct {
-	IPKernel               string  `json:"IP_KERNEL"`
-	PortKernel             int     `json:"PUERTO_KERNEL"`
-	IPMemory               string  `json:"IP_MEMORIA"`
-	PortMemory             int     `json:"PUERTO_MEMORIA"`
-	LogLevel               string  `json:"LOG_LEVEL"`
-	SchedulerAlgorithm     string  `json:"ALGORITMO_CORTO_PLAZO"`
-	ReadyIngressAlgorithm  string  `json:"ALGORITMO_INGRESO_A_READY"`
-	Alpha                  float64 `json:"ALFA"`
-	InitialEstimate        int     `json:"ESTIMACION_INICIAL"`
-	SuspensionTime         int     `json:"TIEMPO_SUSPENSION"`
-	GradoMultiprogramacion int     `json:"GRADO_MULTIPROGRAMACION"`
-	ScriptsPath            string  `json:"SCRIPTS_PATH,omitempty"`
+	IPKernel               string   `json:"IP_KERNEL"`
+	PortKernel             int      `json:"PUERTO_KERNEL"`
+	IPMemory               string   `json:"IP_MEMORIA"`
+	PortMemory             int      `json:"PUERTO_MEMORIA"`
+	LogLevel               string   `json:"LOG_LEVEL"`
+	SchedulerAlgorithm     string   `json:"ALGORITMO_CORTO_PLAZO"`
+	ReadyIngressAlgorithm  string   `json:"ALGORITMO_INGRESO_A_READY"`
+	Alpha                  float64  `json:"ALFA"`
+	InitialEstimate        int      `json:"ESTIMACION_INICIAL"`
+	SuspensionTime         int      `json:"TIEMPO_SUSPENSION"`
+	GradoMultiprogramacion int      `json:"GRADO_MULTIPROGRAMACION"`
+	ScriptsPath            string   `json:"SCRIPTS_PATH,omitempty"`
+	Quantum                int      `json:"QUANTUM,omitempty"`
+	DefaultPriority        int      `json:"PRIORIDAD_POR_DEFECTO,omitempty"`
+	AgingInterval          int      `json:"INTERVALO_AGING,omitempty"`
+	MLFQQuantums           []int    `json:"QUANTUMS_MLFQ,omitempty"`
+	MLFQBoostInterval      int      `json:"INTERVALO_BOOST_MLFQ,omitempty"`
+	DefaultTickets         int      `json:"TICKETS_POR_DEFECTO,omitempty"`
+	MediumTermAlgorithm    string   `json:"ALGORITMO_MEDIANO_PLAZO,omitempty"`
+	SuspensionVictimPolicy string   `json:"VICTIMA_SUSPENSION,omitempty"`
+	ReportFile             string   `json:"ARCHIVO_REPORTE,omitempty"`
+	TraceFile              string   `json:"ARCHIVO_TRAZA,omitempty"`
+	ClockMode              string   `json:"MODO_RELOJ,omitempty"`
+	ClockFactor            float64  `json:"FACTOR_RELOJ,omitempty"`
+	Seed                   int64    `json:"SEMILLA,omitempty"`
+	Resources              []string `json:"RECURSOS,omitempty"`
+	ResourceInstances      []int    `json:"INSTANCIAS_RECURSOS,omitempty"`
+	ResourceWakeOrder      string   `json:"ORDEN_RECURSOS,omitempty"`
+	DeadlockDetection      string   `json:"DETECCION_DEADLOCK,omitempty"`
+	DeadlockInterval       int      `json:"INTERVALO_DEADLOCK,omitempty"`
+	DeadlockRecovery       string   `json:"RECUPERACION_DEADLOCK,omitempty"`
+	DeadlockAvoidance      string   `json:"EVITACION_DEADLOCK,omitempty"`
+	HeartbeatInterval      int      `json:"INTERVALO_LATIDO_CPU,omitempty"`
+	HeartbeatMisses        int      `json:"LATIDOS_PERDIDOS_CPU,omitempty"`
 }
 
 var (
 	kernelModulo  *utils.Modulo
-	kernelConfig  *KernelConfig
 	memoriaClient *utils.HTTPClient
+
+	// configuracionKernel se reemplaza entera al recargar la configuración; se lee con configKernel()
+	configuracionKernel atomic.Pointer[KernelConfig]
+
+	// planificadoresEnMarcha indica si ya se iniciaron los planificadores (y sus rutinas de segundo plano)
+	planificadoresEnMarcha bool
 )
 
+// configKernel devuelve la configuración vigente. La recarga publica una nueva en lugar de modificar
+// la actual, así que la devuelta no cambia mientras se la usa
+func configKernel() *KernelConfig {
+	return configuracionKernel.Load()
+}
+
 // inicializarKernel optimizado
 func inicializarKernel(configPath string) error {
 	kernelModulo = utils.NuevoModulo("Kernel", configPath)
-	kernelConfig = utils.CargarConfiguracion[KernelConfig](configPath)
+	configuracionKernel.Store(utils.CargarConfiguracion[KernelConfig](configPath))
+	rutaConfiguracion = configPath
 
-	utils.InicializarLogger(kernelConfig.LogLevel, "Kernel")
+	utils.InicializarLogger(configKernel().LogLevel, "Kernel")
 	utils.InfoLog.Info("Inicializando Kernel", "config_path", configPath)
 
+	if err := utils.ConfigurarReloj(configKernel().ClockMode, configKernel().ClockFactor); err != nil {
+		return err
+	}
+
 	// Inicializar el mapa de CPUs ANTES de cualquier otra operación
 	inicializarMapaCPUs()
 
-	InicializarPlanificador(kernelConfig)
+	InicializarPlanificador(configKernel())
 
 	// Inicializar y conectar con Memoria
-	memoriaClient = utils.NewHTTPClient(kernelConfig.IPMemory, kernelConfig.PortMemory, "Kernel->Memoria")
+	memoriaClient = utils.NewHTTPClient(configKernel().IPMemory, configKernel().PortMemory, "Kernel->Memoria")
 	if err := conectarAMemoria(10); err != nil {
 		utils.ErrorLog.Error("No se pudo conectar con Memoria", "error", err)
 		return err
 	}
 
 	registrarHandlers()
-	kernelModulo.IniciarServidor(kernelConfig.IPKernel, kernelConfig.PortKernel)
+	registrarRutasAdmin()
+	kernelModulo.IniciarServidor(configKernel().IPKernel, configKernel().PortKernel)
 
 	utils.InfoLog.Info("Kernel inicializado correctamente")
 	return nil
@@ -60,6 +100,8 @@ func inicializarKernel(configPath string) error {
 func registrarHandlers() {
 	kernelModulo.RegistrarHandler(fmt.Sprintf("%d", utils.MensajeHandshake), "handshake", HandlerHandshake)
 	kernelModulo.RegistrarHandler(fmt.Sprintf("%d", utils.MensajeOperacion), "default", HandlerOperacion)
+	kernelModulo.RegistrarHandler(fmt.Sprintf("%d", utils.MensajeRecargarConfiguracion), "default", HandlerRecargarConfiguracion)
+	kernelModulo.RegistrarHandler(fmt.Sprintf("%d", utils.MensajeBaja), "default", HandlerBaja)
 	
 	utils.InfoLog.Info("Handlers registrados correctamente")
 }
@@ -83,11 +125,14 @@ func conectarAMemoria(intentosMax int) error {
 }
 
 // crearYAdmitirProcesoInicial crea el PCB inicial y lo coloca en NEW
-func crearYAdmitirProcesoInicial(nombreArchivo string, tamanio int) {
-	utils.InfoLog.Info("Creando proceso inicial", "archivo", nombreArchivo, "tamaño", tamanio)
+func crearYAdmitirProcesoInicial(nombreArchivo string, tamanio int, prioridad int, opciones map[string]interface{}) {
+	utils.InfoLog.Info("Creando proceso inicial", "archivo", nombreArchivo, "tamaño", tamanio, "prioridad", prioridad, "opciones", opciones)
 	
 	pcb := NuevoPCB(-1, tamanio) // Usar -1 para generar PID 0
 	pcb.NombreArchivo = nombreArchivo
+	pcb.AsignarPrioridad(prioridad)
+	asignarTickets(pcb, opciones)
+	asignarOpcionesTiempoReal(pcb, opciones)
 
 	utils.InfoLog.Info("Proceso inicial creado", "pid", pcb.PID, "estado", "NEW")
 	AgregarProcesoANew(pcb)
@@ -96,8 +141,16 @@ func crearYAdmitirProcesoInicial(nombreArchivo string, tamanio int) {
 // iniciarPlanificadores se llama después de presionar Enter
 func iniciarPlanificadores() {
 	utils.InfoLog.Info("Iniciando planificadores")
+	marcarInicioPlanificacion()
 	go PlanificarLargoPlazo()
 	go PlanificarCortoPlazo()
+	readyMutex.Lock()
+	planificadoresEnMarcha = true
+	if conSegundoPlano, ok := planificadorCortoPlazo().(planificadorConSegundoPlano); ok {
+		conSegundoPlano.IniciarSegundoPlano()
+	}
+	readyMutex.Unlock()
+	iniciarDeteccionPeriodica()
 	utils.InfoLog.Info("Planificadores iniciados")
 }
 
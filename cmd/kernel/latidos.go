@@ -0,0 +1,201 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+// Cada CPU registrada tiene un monitor que le consulta /health cada INTERVALO_LATIDO_CPU ms. Una CPU
+// que no responde deja de recibir procesos y, tras LATIDOS_PERDIDOS_CPU latidos perdidos seguidos,
+// se da de baja: el proceso que ejecutaba vuelve a READY con el último PC conocido. Cada despacho lleva
+// un número y la CPU informa en /health el PC siguiente a la última instrucción que ejecutó: si ya
+// terminó la instrucción despachada, el proceso sigue desde ahí y no la repite. Si la CPU vuelve a
+// hacer el handshake se registra de nuevo con un monitor nuevo
+
+const (
+	intervaloLatidoPorDefecto = 1000 // ms
+	latidosPerdidosPorDefecto = 3
+)
+
+// errCPUCaida indica que la CPU se dio de baja mientras ejecutaba un proceso
+var errCPUCaida = errors.New("CPU dada de baja")
+
+// caidaCPU es el error de un despacho interrumpido por la baja de la CPU. Si la CPU llegó a informar
+// el resultado de la instrucción despachada, pc es el PC desde el que sigue el proceso
+type caidaCPU struct {
+	cpu   string
+	pc    int
+	conPC bool
+}
+
+func (e *caidaCPU) Error() string {
+	return fmt.Sprintf("%s: %s", errCPUCaida, e.cpu)
+}
+
+func (e *caidaCPU) Unwrap() error {
+	return errCPUCaida
+}
+
+// monitorCPU sigue los latidos de una CPU. perdidos y el último informe se protegen con cpuClientsMutex
+type monitorCPU struct {
+	cliente  *utils.HTTPClient
+	perdidos int
+	caida    chan struct{} // Se cierra cuando la CPU se da de baja
+
+	despachoInformado int // Despacho de la última instrucción que la CPU informó en /health
+	pcInformado       int // PC siguiente a esa instrucción
+}
+
+var (
+	// monitoresCPU y ultimoDespacho se protegen con cpuClientsMutex, igual que cpuClients
+	monitoresCPU   = make(map[string]*monitorCPU)
+	ultimoDespacho int
+)
+
+// iniciarMonitorCPU empieza a vigilar una CPU recién registrada. Si ya tenía un monitor, la CPU se
+// reconectó: la instancia anterior se da por caída. Requiere cpuClientsMutex tomado
+func iniciarMonitorCPU(nombre string, ip string, puerto int) {
+	if anterior, existe := monitoresCPU[nombre]; existe {
+		utils.InfoLog.Info("CPU reconectada, se descarta la instancia anterior", "cpu", nombre)
+		close(anterior.caida)
+	}
+	delete(cpusDrenando, nombre)
+
+	monitor := &monitorCPU{
+		cliente: utils.NewHTTPClientConTimeout(ip, puerto, "Kernel->"+nombre, intervaloLatido()),
+		caida:   make(chan struct{}),
+	}
+	monitoresCPU[nombre] = monitor
+	go vigilarCPU(nombre, monitor)
+}
+
+func vigilarCPU(nombre string, monitor *monitorCPU) {
+	for {
+		time.Sleep(intervaloLatido())
+
+		cpuClientsMutex.Lock()
+		vigente := monitoresCPU[nombre] == monitor
+		cpuClientsMutex.Unlock()
+		if !vigente {
+			return
+		}
+
+		estado, err := monitor.cliente.Latido()
+
+		cpuClientsMutex.Lock()
+		if monitoresCPU[nombre] != monitor {
+			cpuClientsMutex.Unlock()
+			return
+		}
+		if err == nil {
+			registrarInformeCPU(monitor, estado)
+			if monitor.perdidos > 0 {
+				utils.InfoLog.Info("CPU responde nuevamente", "cpu", nombre, "latidos_perdidos", monitor.perdidos)
+			}
+			monitor.perdidos = 0
+			cpuClientsMutex.Unlock()
+			continue
+		}
+
+		monitor.perdidos++
+		perdidos := monitor.perdidos
+		if perdidos < latidosPerdidosMaximos() {
+			cpuClientsMutex.Unlock()
+			utils.InfoLog.Warn("Latido de CPU perdido", "cpu", nombre, "latidos_perdidos", perdidos, "error", err)
+			continue
+		}
+
+		quitarCPU(nombre)
+		close(monitor.caida)
+		cpuClientsMutex.Unlock()
+
+		utils.ErrorLog.Error("CPU dada de baja por latidos perdidos", "cpu", nombre, "latidos_perdidos", perdidos, "error", err)
+		return
+	}
+}
+
+// registrarInformeCPU guarda el PC que la CPU informó para su último despacho. Requiere cpuClientsMutex tomado
+func registrarInformeCPU(monitor *monitorCPU, estado map[string]interface{}) {
+	despacho, hayDespacho := estado["despacho"].(float64)
+	pc, hayPC := estado["pc"].(float64)
+	if hayDespacho && hayPC {
+		monitor.despachoInformado = int(despacho)
+		monitor.pcInformado = int(pc)
+	}
+}
+
+// registrarFalloCPU cuenta un latido perdido cuando falla un despacho, para que no se le vuelva a
+// despachar hasta que responda un latido
+func registrarFalloCPU(nombre string) {
+	cpuClientsMutex.Lock()
+	defer cpuClientsMutex.Unlock()
+	if monitor, existe := monitoresCPU[nombre]; existe && monitor.perdidos == 0 {
+		monitor.perdidos = 1
+	}
+}
+
+// cpuResponde indica si la CPU no tiene latidos perdidos. Requiere cpuClientsMutex tomado
+func cpuResponde(nombre string) bool {
+	monitor, existe := monitoresCPU[nombre]
+	return !existe || monitor.perdidos == 0
+}
+
+// latidosPerdidosCPU devuelve los latidos perdidos seguidos de la CPU. Requiere cpuClientsMutex tomado
+func latidosPerdidosCPU(nombre string) int {
+	if monitor, existe := monitoresCPU[nombre]; existe {
+		return monitor.perdidos
+	}
+	return 0
+}
+
+// ejecutarEnCPU envía la ráfaga y espera la respuesta de la CPU, salvo que se dé de baja antes.
+// En ese caso la respuesta que pueda llegar después se descarta y el error lleva el último PC informado
+func ejecutarEnCPU(nombreCPU string, cpuClient *utils.HTTPClient, datos map[string]interface{}) (interface{}, error) {
+	cpuClientsMutex.Lock()
+	ultimoDespacho++
+	despacho := ultimoDespacho
+	monitor := monitoresCPU[nombreCPU]
+	var caida chan struct{}
+	if monitor != nil {
+		caida = monitor.caida
+	}
+	cpuClientsMutex.Unlock()
+	datos["despacho"] = despacho
+
+	type resultado struct {
+		respuesta interface{}
+		err       error
+	}
+	listo := make(chan resultado, 1)
+	go func() {
+		respuesta, err := cpuClient.EnviarHTTPOperacion("EJECUTAR_PROCESO", datos)
+		listo <- resultado{respuesta, err}
+	}()
+
+	select {
+	case r := <-listo:
+		return r.respuesta, r.err
+	case <-caida:
+		cpuClientsMutex.Lock()
+		defer cpuClientsMutex.Unlock()
+		return nil, &caidaCPU{cpu: nombreCPU, pc: monitor.pcInformado, conPC: monitor.despachoInformado == despacho}
+	}
+}
+
+func intervaloLatido() time.Duration {
+	intervalo := configKernel().HeartbeatInterval
+	if intervalo <= 0 {
+		intervalo = intervaloLatidoPorDefecto
+	}
+	return time.Duration(intervalo) * time.Millisecond
+}
+
+func latidosPerdidosMaximos() int {
+	if configKernel().HeartbeatMisses <= 0 {
+		return latidosPerdidosPorDefecto
+	}
+	return configKernel().HeartbeatMisses
+}
@@ -1,12 +1,10 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"os/signal"
 	"strconv"
-	"strings"
 	"syscall"
 
 	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
@@ -20,7 +18,7 @@ func main() {
 
 	// Verificar argumentos mínimos
 	if len(os.Args) < 4 {
-		fmt.Fprintf(os.Stderr, "Uso: %s <archivo_configuracion> <archivo_pseudocódigo> <tamaño>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Uso: %s <archivo_configuracion> <archivo_pseudocódigo> <tamaño> [prioridad] [TICKETS=N] [PERIODO=ms DEADLINE=ms WCET=ms]\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Ejemplo: %s configs/kernel-config-PlaniCortoFIFO scripts/PLANI_CORTO_PLAZO 0\n", os.Args[0])
 		os.Exit(1)
 	}
@@ -34,6 +32,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Prioridad opcional del proceso inicial (-1 = usar PRIORIDAD_POR_DEFECTO) y opciones CLAVE=VALOR
+	prioridadInicial, opcionesIniciales, err := parsearParametrosProceso(os.Args[4:])
+	if err != nil {
+		utils.ErrorLog.Error("Parámetros inválidos para el proceso inicial", "error", err)
+		os.Exit(1)
+	}
+
 	// Verificar que el archivo de configuración existe
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		utils.ErrorLog.Error("El archivo de configuración no existe", "archivo", configPath)
@@ -43,7 +48,9 @@ func main() {
 	utils.InfoLog.Info("Parámetros procesados", 
 		"config", configPath, 
 		"script", nombreArchivoInicial, 
-		"tamaño", tamanioInicial)
+		"tamaño", tamanioInicial,
+		"prioridad", prioridadInicial,
+		"opciones", opcionesIniciales)
 
 	// Inicializar kernel
 	err = inicializarKernel(configPath)
@@ -52,21 +59,16 @@ func main() {
 		os.Exit(1)
 	}
 
+	escucharSIGHUP()
+
 	// Crear proceso inicial
-	crearYAdmitirProcesoInicial(nombreArchivoInicial, tamanioInicial)
+	crearYAdmitirProcesoInicial(nombreArchivoInicial, tamanioInicial, prioridadInicial, opcionesIniciales)
 
 	utils.InfoLog.Info("Kernel listo y esperando conexiones")
 
-	// Esperar Enter para iniciar planificadores
-	fmt.Println("Presione ENTER para iniciar los planificadores...")
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(input)
-
-	utils.InfoLog.Info("Enter presionado, iniciando planificadores")
-	fmt.Println("Planificadores iniciados. Sistema funcionando...")
-
-	iniciarPlanificadores()
+	// La consola inicia los planificadores con INICIAR (o ENTER)
+	fmt.Println("Presione ENTER o escriba INICIAR para iniciar los planificadores (AYUDA para ver los comandos)")
+	go ejecutarConsola(os.Stdin)
 
 	// Configurar manejo de señales
 	sigChan := make(chan os.Signal, 1)
@@ -75,6 +77,7 @@ func main() {
 	// Esperar señal de terminación
 	<-sigChan
 	utils.InfoLog.Info("Ctrl+C recibido. Finalizando Kernel")
+	fmt.Println("\n" + emitirReporte())
 	fmt.Println("\nKernel finalizando...")
 	os.Exit(0)
 }
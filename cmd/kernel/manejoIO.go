@@ -1,7 +1,9 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"sort"
 	"strings"
 	"sync"
@@ -14,6 +16,10 @@ var (
 	dispositivosIOMutex sync.RWMutex
 	contadorBalanceador int
 	balanceadorMutex    sync.Mutex
+
+	// ioEnCurso registra los PIDs con una petición en curso en cada dispositivo
+	ioEnCurso      = make(map[string][]int)
+	ioEnCursoMutex sync.Mutex
 )
 
 // RegistrarDispositivoIO optimizado
@@ -34,16 +40,37 @@ func ObtenerClienteIO(nombre string) (*utils.HTTPClient, bool) {
 	return cliente, existe
 }
 
-// EnviarSolicitudIO con la corrección definitiva
+// EnviarSolicitudIO envía al dispositivo la petición que tiene el turno en su cola. Si el dispositivo no
+// responde se quita del pool y su cola pasa a otras instancias de la misma clase
 func EnviarSolicitudIO(pcb *PCB, dispositivo string, tiempo int) {
-	cliente, existe := ObtenerClienteIO(dispositivo)
-	if !existe {
-		utils.ErrorLog.Error("Dispositivo IO no registrado, finalizando proceso", "dispositivo", dispositivo, "pid", pcb.PID)
-		FinalizarProceso(pcb, "ERROR_IO_DEVICE_NOT_FOUND") // Finaliza si el dispositivo no existe
+	cliente, _ := ObtenerClienteIO(dispositivo)
+	err := enviarPeticionIO(cliente, pcb, dispositivo, tiempo)
+	if err == nil {
 		return
 	}
 
+	// Un timeout no es una falla: el dispositivo sigue atendiendo y avisa con IO_TERMINADA
+	if esTimeoutIO(err) {
+		utils.InfoLog.Warn("Petición de IO sin respuesta a tiempo, se espera IO_TERMINADA", "pid", pcb.PID, "dispositivo", dispositivo)
+		return
+	}
+
+	utils.ErrorLog.Error("Error de comunicación con dispositivo IO", "dispositivo", dispositivo, "pid", pcb.PID, "error", err.Error())
+	quitarIOEnCurso(pcb.PID)
+	if quitarDispositivoIO(dispositivo) {
+		utils.InfoLog.Warn("Dispositivo IO quitado por no responder", "dispositivo", dispositivo)
+	}
+	reubicarColaIO(dispositivo, true)
+}
+
+// enviarPeticionIO envía una petición a una instancia y espera a que la atienda
+func enviarPeticionIO(cliente *utils.HTTPClient, pcb *PCB, dispositivo string, tiempo int) error {
+	if cliente == nil {
+		return fmt.Errorf("dispositivo %s dado de baja", dispositivo)
+	}
+
 	utils.InfoLog.Info("Enviando petición a IO", "pid", pcb.PID, "dispositivo", dispositivo)
+	registrarIOEnCurso(dispositivo, pcb.PID)
 
 	datos := map[string]interface{}{
 		"pid":       pcb.PID,
@@ -51,15 +78,68 @@ func EnviarSolicitudIO(pcb *PCB, dispositivo string, tiempo int) {
 		"operacion": "IO_REQUEST",
 	}
 
-	_, err := cliente.EnviarHTTPOperacion("IO_REQUEST", datos)
+	if _, err := cliente.EnviarHTTPOperacion("IO_REQUEST", datos); err != nil {
+		return fmt.Errorf("petición a %s: %w", dispositivo, err)
+	}
+	return nil
+}
+
+func esTimeoutIO(err error) bool {
+	var errRed net.Error
+	return errors.As(err, &errRed) && errRed.Timeout()
+}
+
+// claseDispositivo es el nombre del dispositivo sin los dígitos finales: DISCO1 y DISCO2 son DISCO
+func claseDispositivo(nombre string) string {
+	return strings.TrimRight(nombre, "0123456789")
+}
 
-	// --- CAMBIO CLAVE Y DEFINITIVO ---
-	// Si hay un error de comunicación (ej: el IO está caído), finalizamos el proceso.
-	if err != nil {
-		utils.ErrorLog.Error("Error de comunicación con dispositivo IO. El proceso será finalizado.", "dispositivo", dispositivo, "pid", pcb.PID, "error", err.Error())
-		FinalizarProceso(pcb, "ERROR_IO_CONNECTION")
-		return // Importante: Salimos de la función aquí.
+// esAliasIO indica si el nombre es el registro "IO<nombre>" de un dispositivo también registrado por su nombre
+func esAliasIO(nombre string) bool {
+	simple := strings.TrimPrefix(nombre, "IO")
+	if simple == nombre {
+		return false
 	}
+	_, existe := dispositivosIO[simple]
+	return existe
+}
+
+// otraInstanciaIO elige otra instancia registrada de la misma clase que el dispositivo
+func otraInstanciaIO(dispositivo string) (string, bool) {
+	dispositivosIOMutex.RLock()
+	defer dispositivosIOMutex.RUnlock()
+
+	instancias := obtenerDispositivosSimilares(dispositivo)
+	for i, nombre := range instancias {
+		if nombre == dispositivo {
+			instancias = append(instancias[:i], instancias[i+1:]...)
+			break
+		}
+	}
+	if len(instancias) == 0 {
+		return "", false
+	}
+
+	balanceadorMutex.Lock()
+	alternativo := instancias[contadorBalanceador%len(instancias)]
+	contadorBalanceador++
+	balanceadorMutex.Unlock()
+	return alternativo, true
+}
+
+// quitarDispositivoIO saca del pool a una instancia junto con su alias "IO<nombre>" y devuelve si estaba
+// registrada. Si vuelve a hacer el handshake se registra de nuevo
+func quitarDispositivoIO(dispositivo string) bool {
+	dispositivosIOMutex.Lock()
+	defer dispositivosIOMutex.Unlock()
+
+	simple := strings.TrimPrefix(dispositivo, "IO")
+	_, existe := dispositivosIO[dispositivo]
+	_, existeSimple := dispositivosIO[simple]
+	delete(dispositivosIO, dispositivo)
+	delete(dispositivosIO, simple)
+	delete(dispositivosIO, "IO"+simple)
+	return existe || existeSimple
 }
 
 // manejarCompletionIO maneja la finalización de IO considerando el estado actual del proceso
@@ -143,7 +223,7 @@ func ProcesarSolicitudIO(datos map[string]interface{}) (interface{}, bool) {
 
 	dispositivoSeleccionado := SeleccionarDispositivoIO(dispositivo, pcb.PID)
 	MoverProcesoABlocked(pcb, fmt.Sprintf("IO_%s", dispositivoSeleccionado))
-	go EnviarSolicitudIO(pcb, dispositivoSeleccionado, int(tiempoFloat))
+	go encolarIO(pcb, dispositivoSeleccionado, int(tiempoFloat))
 	go despacharProcesoSiCorresponde()
 
 	return map[string]interface{}{"status": "OK", "mensaje": "IO procesando"}, true
@@ -163,6 +243,9 @@ func ProcesarIOTerminada(datos map[string]interface{}) (interface{}, bool) {
 		return map[string]interface{}{"status": "ERROR", "mensaje": "PID inválido"}, true
 	}
 
+	quitarIOEnCurso(int(pidFloat))
+	avanzarColaIO(int(pidFloat))
+
 	pcb := BuscarPCBPorPID(int(pidFloat))
 	if pcb == nil {
 		return map[string]interface{}{"status": "ERROR", "mensaje": "Proceso no encontrado"}, true
@@ -189,6 +272,34 @@ func ProcesarIOTerminada(datos map[string]interface{}) (interface{}, bool) {
 	return map[string]interface{}{"status": "OK", "mensaje": "IO completada"}, true
 }
 
+// registrarIOEnCurso anota que el dispositivo está atendiendo al proceso
+func registrarIOEnCurso(dispositivo string, pid int) {
+	ioEnCursoMutex.Lock()
+	defer ioEnCursoMutex.Unlock()
+	ioEnCurso[dispositivo] = append(ioEnCurso[dispositivo], pid)
+	registrarInicioOcupacionIO(dispositivo)
+	trazarEvento(EventoIOInicio, pid, dispositivo, "")
+}
+
+// quitarIOEnCurso borra la petición en curso del proceso, cualquiera sea el dispositivo
+func quitarIOEnCurso(pid int) {
+	ioEnCursoMutex.Lock()
+	defer ioEnCursoMutex.Unlock()
+
+	for dispositivo, pids := range ioEnCurso {
+		for i, enCurso := range pids {
+			if enCurso == pid {
+				ioEnCurso[dispositivo] = append(pids[:i], pids[i+1:]...)
+				trazarEvento(EventoIOFin, pid, dispositivo, "")
+				if len(ioEnCurso[dispositivo]) == 0 {
+					registrarFinOcupacionIO(dispositivo)
+				}
+				return
+			}
+		}
+	}
+}
+
 // SeleccionarDispositivoIO implementa balanceador de carga
 func SeleccionarDispositivoIO(dispositivoSolicitado string, pid int) string {
 	dispositivosIOMutex.RLock()
@@ -217,12 +328,16 @@ func SeleccionarDispositivoIO(dispositivoSolicitado string, pid int) string {
 	return dispositivoSeleccionado
 }
 
-// obtenerDispositivosSimilares devuelve lista de dispositivos IO disponibles
+// obtenerDispositivosSimilares devuelve las instancias registradas de la misma clase que el dispositivo
+// (sin los alias "IO<nombre>"). Requiere dispositivosIOMutex tomado
 func obtenerDispositivosSimilares(dispositivoBase string) []string {
 	var dispositivos []string
 
+	clase := claseDispositivo(dispositivoBase)
 	for nombre := range dispositivosIO {
-		dispositivos = append(dispositivos, nombre)
+		if !esAliasIO(nombre) && claseDispositivo(nombre) == clase {
+			dispositivos = append(dispositivos, nombre)
+		}
 	}
 
 	sort.Strings(dispositivos)
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+// Algoritmos de mediano plazo (ALGORITMO_MEDIANO_PLAZO)
+const (
+	MedianoPlazoTimer          = "TIMER"
+	MedianoPlazoPresionMemoria = "PRESION_MEMORIA"
+)
+
+// Políticas de elección de víctima (VICTIMA_SUSPENSION)
+const (
+	VictimaMasAntigua     = "MAS_ANTIGUO"
+	VictimaMasReciente    = "MAS_RECIENTE"
+	VictimaMasGrande      = "MAS_GRANDE"
+	VictimaMenorPrioridad = "MENOR_PRIORIDAD"
+)
+
+// algoritmoMedianoPlazo devuelve el algoritmo configurado (TIMER por defecto)
+func algoritmoMedianoPlazo() string {
+	if configKernel().MediumTermAlgorithm == "" {
+		return MedianoPlazoTimer
+	}
+	return configKernel().MediumTermAlgorithm
+}
+
+// suspensionPorPresion indica si los procesos bloqueados solo se suspenden cuando falta memoria
+func suspensionPorPresion() bool {
+	return algoritmoMedianoPlazo() == MedianoPlazoPresionMemoria
+}
+
+// consultarEspacioLibre pregunta a Memoria cuántos bytes libres tiene
+func consultarEspacioLibre() (int, bool) {
+	cliente := GetMemoriaClient()
+	if cliente == nil {
+		utils.ErrorLog.Error("No se pudo obtener cliente de memoria para consultar espacio libre")
+		return 0, false
+	}
+
+	respuesta, err := cliente.EnviarHTTPMensaje(utils.MensajeEspacioLibre, "default", nil)
+	if err != nil {
+		utils.ErrorLog.Error("Error consultando espacio libre a Memoria", "error", err.Error())
+		return 0, false
+	}
+
+	if respuestaMap, ok := respuesta.(map[string]interface{}); ok {
+		if espacio, hayEspacio := respuestaMap["espacio_libre"].(float64); hayEspacio {
+			return int(espacio), true
+		}
+	}
+
+	utils.ErrorLog.Error("Respuesta de espacio libre en formato inválido", "respuesta", fmt.Sprintf("%v", respuesta))
+	return 0, false
+}
+
+// elegirVictimaSuspension elige entre los procesos en BLOCKED según VICTIMA_SUSPENSION.
+// Los procesos de tamaño 0 no liberan memoria y se descartan
+func elegirVictimaSuspension() *PCB {
+	blockedMutex.Lock()
+	bloqueados := append([]*PCB{}, colaBlocked...)
+	blockedMutex.Unlock()
+
+	// Los procesos con hilos secundarios tampoco: otros hilos siguen usando su memoria
+	var victima *PCB
+	for _, pcb := range bloqueados {
+		if pcb.Tamanio == 0 || tieneHilosVivos(pcb) {
+			continue
+		}
+		if victima == nil || esMejorVictima(pcb, victima) {
+			victima = pcb
+		}
+	}
+	return victima
+}
+
+// esMejorVictima indica si conviene suspender a a antes que a b
+func esMejorVictima(a, b *PCB) bool {
+	switch configKernel().SuspensionVictimPolicy {
+	case VictimaMasReciente:
+		return a.HoraBloqueo.After(b.HoraBloqueo)
+	case VictimaMasGrande:
+		if a.Tamanio != b.Tamanio {
+			return a.Tamanio > b.Tamanio
+		}
+	case VictimaMenorPrioridad:
+		if a.Prioridad != b.Prioridad {
+			return a.Prioridad > b.Prioridad
+		}
+	}
+	return a.HoraBloqueo.Before(b.HoraBloqueo)
+}
+
+// liberarMemoriaPara suspende procesos bloqueados hasta que el proceso entre en memoria.
+// Se llama antes de esperar el semáforo de multiprogramación. Devuelve si hay espacio suficiente al terminar
+func liberarMemoriaPara(pcb *PCB) bool {
+	for {
+		espacioLibre, ok := consultarEspacioLibre()
+		if !ok {
+			return false
+		}
+		if espacioLibre >= pcb.Tamanio {
+			utils.InfoLog.Info("Proceso entra en memoria", "pid", pcb.PID, "tamaño", pcb.Tamanio, "espacio_libre", espacioLibre)
+			return true
+		}
+
+		victima := elegirVictimaSuspension()
+		if victima == nil {
+			utils.InfoLog.Warn("Memoria insuficiente y sin procesos bloqueados para suspender", "pid", pcb.PID, "tamaño", pcb.Tamanio, "espacio_libre", espacioLibre)
+			return false
+		}
+
+		utils.InfoLog.Info(fmt.Sprintf("(%d) - Suspendido por presión de memoria", victima.PID))
+		utils.InfoLog.Info("Suspensión por presión de memoria",
+			"victima", victima.PID,
+			"politica", configKernel().SuspensionVictimPolicy,
+			"para_pid", pcb.PID,
+			"tamaño_requerido", pcb.Tamanio,
+			"espacio_libre", espacioLibre)
+
+		if !suspenderProceso(victima.PID) {
+			return false
+		}
+	}
+}
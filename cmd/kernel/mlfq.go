@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+// Quantums por defecto si no se configura QUANTUMS_MLFQ
+var quantumsMLFQPorDefecto = []int{500, 1000, 2000}
+
+// colasMLFQ reemplaza a colaReady cuando el algoritmo es MLFQ (índice 0 = máxima prioridad)
+var colasMLFQ [][]*PCB
+
+func init() {
+	RegistrarPlanificador(TipoCortoPlazo, "MLFQ", func() Planificador { return nuevoPlanificadorMLFQ() })
+}
+
+// planificadorMLFQ implementa colas multinivel con realimentación
+type planificadorMLFQ struct {
+	planificadorBase
+}
+
+// nuevoPlanificadorMLFQ crea una cola de READY por nivel configurado
+func nuevoPlanificadorMLFQ() *planificadorMLFQ {
+	colasMLFQ = make([][]*PCB, len(quantumsMLFQ()))
+	for nivel := range colasMLFQ {
+		colasMLFQ[nivel] = []*PCB{}
+	}
+
+	utils.InfoLog.Info("Colas MLFQ inicializadas", "niveles", len(colasMLFQ), "quantums_ms", quantumsMLFQ())
+	return &planificadorMLFQ{}
+}
+
+// quantumsMLFQ devuelve los quantums configurados por nivel o los valores por defecto
+func quantumsMLFQ() []int {
+	if len(configKernel().MLFQQuantums) == 0 {
+		return quantumsMLFQPorDefecto
+	}
+	return configKernel().MLFQQuantums
+}
+
+// SeleccionarSiguiente toma el primer proceso del nivel más prioritario con procesos
+func (p *planificadorMLFQ) SeleccionarSiguiente() *PCB {
+	for _, cola := range colasMLFQ {
+		if len(cola) > 0 {
+			utils.InfoLog.Info("MLFQ seleccionó proceso", "pid", cola[0].PID, "nivel", cola[0].NivelMLFQ)
+			return cola[0]
+		}
+	}
+	return nil
+}
+
+// DebeDesalojar elige el proceso en ejecución de nivel más bajo que el candidato
+func (p *planificadorMLFQ) DebeDesalojar(candidato *PCB, enEjecucion []*PCB) *PCB {
+	var procesoADesalojar *PCB
+	for _, pcbEnExec := range enEjecucion {
+		if pcbEnExec.NivelMLFQ > candidato.NivelMLFQ {
+			if procesoADesalojar == nil || pcbEnExec.NivelMLFQ > procesoADesalojar.NivelMLFQ {
+				procesoADesalojar = pcbEnExec
+			}
+		}
+	}
+
+	if procesoADesalojar != nil {
+		utils.InfoLog.Info(fmt.Sprintf("(%d) - Desalojado por algoritmo MLFQ", procesoADesalojar.PID))
+	}
+	return procesoADesalojar
+}
+
+func (p *planificadorMLFQ) AlPasarAReady(pcb *PCB) {
+	// Tras recargar la configuración puede haber menos niveles
+	if pcb.NivelMLFQ >= len(colasMLFQ) {
+		pcb.NivelMLFQ = len(colasMLFQ) - 1
+	}
+	colasMLFQ[pcb.NivelMLFQ] = append(colasMLFQ[pcb.NivelMLFQ], pcb)
+	utils.InfoLog.Info("Proceso encolado en READY (MLFQ)", "pid", pcb.PID, "nivel", pcb.NivelMLFQ)
+}
+
+// AlBloquearse sube un nivel al proceso que cede la CPU para hacer IO. Los bloqueos por
+// recursos, hilos o IPC no lo promueven
+func (p *planificadorMLFQ) AlBloquearse(pcb *PCB, motivo string) {
+	if !esBloqueoPorIO(motivo) || pcb.NivelMLFQ == 0 {
+		return
+	}
+	pcb.NivelMLFQ--
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - MLFQ: asciende al nivel %d", pcb.PID, pcb.NivelMLFQ))
+}
+
+// AlFinalizarRafaga baja un nivel al proceso que vuelve a READY por agotar su quantum
+func (p *planificadorMLFQ) AlFinalizarRafaga(pcb *PCB, nuevoEstado string) {
+	if nuevoEstado != EstadoReady || !pcb.QuantumAgotado || pcb.NivelMLFQ >= len(colasMLFQ)-1 {
+		return
+	}
+	pcb.NivelMLFQ++
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - MLFQ: desciende al nivel %d", pcb.PID, pcb.NivelMLFQ))
+}
+
+func (p *planificadorMLFQ) QuantumPara(pcb *PCB) float64 {
+	quantums := quantumsMLFQ()
+	nivel := pcb.NivelMLFQ
+	if nivel < 0 || nivel >= len(quantums) || quantums[nivel] <= 0 {
+		return quantumPorDefecto
+	}
+	return float64(quantums[nivel])
+}
+
+func (p *planificadorMLFQ) IniciarSegundoPlano() {
+	go iniciarBoostMLFQ(p)
+}
+
+// iniciarBoostMLFQ lleva periódicamente todos los procesos al nivel más prioritario
+// mientras el planificador siga en uso
+func iniciarBoostMLFQ(planificador Planificador) {
+	intervalo := time.Duration(configKernel().MLFQBoostInterval) * time.Millisecond
+	if intervalo <= 0 {
+		utils.InfoLog.Warn("INTERVALO_BOOST_MLFQ no configurado, boost de prioridades deshabilitado")
+		return
+	}
+
+	utils.InfoLog.Info("Iniciando boost de prioridades MLFQ", "intervalo_ms", intervalo.Milliseconds())
+
+	for {
+		utils.Dormir(intervalo)
+		if !planificadorVigente(planificador) {
+			utils.InfoLog.Info("Boost de prioridades MLFQ detenido: el planificador fue reemplazado")
+			return
+		}
+		aplicarBoostMLFQ()
+		condReady.Signal()
+	}
+}
+
+// aplicarBoostMLFQ mueve todos los procesos al nivel 0 respetando el orden entre niveles
+func aplicarBoostMLFQ() {
+	mapaMutex.RLock()
+	for _, pcb := range mapaPCBs {
+		pcb.NivelMLFQ = 0
+	}
+	mapaMutex.RUnlock()
+
+	readyMutex.Lock()
+	defer readyMutex.Unlock()
+
+	movidos := 0
+	for nivel := 1; nivel < len(colasMLFQ); nivel++ {
+		movidos += len(colasMLFQ[nivel])
+		colasMLFQ[0] = append(colasMLFQ[0], colasMLFQ[nivel]...)
+		colasMLFQ[nivel] = []*PCB{}
+	}
+
+	utils.InfoLog.Info("Boost MLFQ aplicado", "procesos_movidos_a_nivel_0", movidos)
+}
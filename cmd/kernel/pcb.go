@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
@@ -17,6 +19,9 @@ const (
 	EstadoExit        = "EXIT"
 )
 
+// estadosMetricas es el orden en que se informan las métricas de estado
+var estadosMetricas = []string{EstadoNew, EstadoReady, EstadoExec, EstadoBlocked, EstadoSuspBlocked, EstadoSuspReady}
+
 type PCB struct {
 	PID                       int
 	Estado                    string
@@ -32,26 +37,83 @@ type PCB struct {
 	HoraBloqueo      time.Time
 	HoraFinalizacion time.Time
 
+	// Contabilidad por estado: cantidad de ingresos y tiempo acumulado (ms), protegida por metricasMutex
+	ContadorEstados      map[string]int
+	TiempoEstados        map[string]float64
+	metricasMutex        sync.Mutex
+	HoraEntradaEstado    time.Time
+	HoraPrimeraEjecucion time.Time
+
 	// Tracking de ejecución
 	UltimaRafagaReal     float64
 	InicioUltimaRafaga   time.Time
 	TotalEjecuciones     int
 	TotalTiempoEjecucion float64
 	MotivoBloqueo        string
+	MotivoFinalizacion   string
+	CPUAsignada          string // CPU del despacho actual (o el último)
 
 	// Flag para distinguir si el proceso está realmente en SWAP o ya fue cargado por IO
 	EnSwap bool
+
+	// Round Robin / Virtual Round Robin
+	QuantumAsignado float64 // Quantum (ms) otorgado en el despacho actual
+	QuantumRestante float64 // Quantum no consumido al bloquearse (solo VRR)
+
+	// Prioridades (menor número = mayor prioridad)
+	PrioridadBase int       // Prioridad asignada en la creación
+	Prioridad     int       // Prioridad efectiva, mejorada por aging mientras espera en READY
+	UltimoAging   time.Time // Último instante en que se aplicó aging al proceso
+
+	// MLFQ
+	NivelMLFQ      int  // Nivel de READY en el que se encola (0 = máxima prioridad)
+	QuantumAgotado bool // El último desalojo fue por fin de quantum
+
+	// Planificación proporcional (LOTTERY / STRIDE)
+	Tickets             int     // Boletos asignados en la creación, heredados por INIT_PROC
+	Pase                float64 // Avance acumulado en STRIDE (menor = siguiente en ejecutar)
+	CPUSistemaAlCrear   float64 // Tiempo de CPU total del sistema (ms) al crearse el proceso
+	CPUEsperadoPorCuota float64 // Tiempo de CPU (ms) que le correspondía según sus tickets
+
+	// Tiempo real (EDF / RM). Tiempos en milisegundos
+	TiempoReal        bool
+	Periodo           float64   // Separación mínima entre activaciones
+	Deadline          float64   // Deadline relativo a cada activación
+	WCET              float64   // Peor tiempo de ejecución por activación
+	ActivacionEnCurso bool      // Hay una activación liberada que todavía no terminó
+	NumeroActivacion  int       // Contador de activaciones liberadas
+	InicioActivacion  time.Time // Instante de liberación de la activación en curso
+	DeadlineAbsoluto  time.Time // Vencimiento de la activación en curso
+	DeadlinesPerdidos int
+	timerDeadline     utils.Temporizador
+
+	// Jerarquía de procesos (protegida por jerarquiaMutex)
+	PadrePID         int          // PID del creador por INIT_PROC (sinPadre si lo creó el kernel)
+	Hijos            []int        // PIDs de los hijos vivos
+	EsperaHijo       int          // Hijo que espera en WAIT (esperaCualquierHijo = ANY, sinEsperaHijo si no espera)
+	ultimoWait       *salidaHijo  // Hijo entregado por el último WAIT (PID -1 si no había hijos, nil si nunca hizo WAIT)
+	hijosFinalizados []salidaHijo // Hijos terminados que todavía no recolectó un WAIT
+
+	// Hilos (protegidos por hilosMutex)
+	TID           int    // Identificador del hilo dentro del proceso (tidPrincipal en el hilo principal)
+	Proceso       *PCB   // Hilo principal del proceso (nil en el propio hilo principal)
+	Hilos         []*PCB // Hilos secundarios vivos (solo en el hilo principal)
+	ProximoTID    int    // TID que recibirá el próximo THREAD_CREATE
+	EsperaHilo    int    // TID que espera en THREAD_JOIN (sinEsperaHilo si no espera)
+	hilosCerrados bool   // El proceso está finalizando y no admite hilos nuevos
+
+	entregaIPC *entregaIPC // Datos recibidos por IPC mientras estaba bloqueado, pendientes de escribir en su memoria
 }
 
 // NuevoPCB simplificado
 func NuevoPCB(pid int, tamanio int) *PCB {
-	horaActual := time.Now()
+	horaActual := utils.Ahora()
 	finalPID := pid
 	if pid < 0 {
 		finalPID = GenerarNuevoPID()
 	}
 
-	estimacionInicial := float64(kernelConfig.InitialEstimate)
+	estimacionInicial := float64(configKernel().InitialEstimate)
 	if estimacionInicial <= 0 {
 		estimacionInicial = 5000.0
 	}
@@ -62,8 +124,19 @@ func NuevoPCB(pid int, tamanio int) *PCB {
 		Tamanio:                   tamanio,
 		PC:                        0,
 		EstimacionSiguienteRafaga: estimacionInicial,
+		PrioridadBase:             configKernel().DefaultPriority,
+		Prioridad:                 configKernel().DefaultPriority,
+		Tickets:                   ticketsPorDefecto(),
+		CPUSistemaAlCrear:         tiempoCPUSistema(),
 		HoraCreacion:              horaActual,
 		EnSwap:                    false, // Los procesos nuevos no están en SWAP
+		ContadorEstados:           map[string]int{EstadoNew: 1},
+		TiempoEstados:             map[string]float64{},
+		HoraEntradaEstado:         horaActual,
+		PadrePID:                  sinPadre,
+		EsperaHijo:                sinEsperaHijo,
+		ProximoTID:                tidPrincipal + 1,
+		EsperaHilo:                sinEsperaHilo,
 	}
 
 	mapaMutex.Lock()
@@ -71,24 +144,30 @@ func NuevoPCB(pid int, tamanio int) *PCB {
 	mapaMutex.Unlock()
 
 	utils.InfoLog.Info(fmt.Sprintf("(%d) - Se crea el proceso - Estado: %s", pcb.PID, pcb.Estado))
+	registrarEventoTraza(EventoTraza{Marca: horaActual, Tipo: EventoEstado, PID: pcb.PID, Hacia: EstadoNew})
 
 	return pcb
 }
 
 // CambiarEstado optimizado
 func (pcb *PCB) CambiarEstado(nuevoEstado string) {
-	if pcb.Estado == nuevoEstado {
+	// Un proceso en EXIT no vuelve a ningún otro estado
+	if pcb.Estado == nuevoEstado || pcb.Estado == EstadoExit {
 		return
 	}
 
 	estadoAnterior := pcb.Estado
-	horaActual := time.Now()
+	horaActual := utils.Ahora()
 
 	// Manejar transiciones de ejecución
 	switch {
 	case estadoAnterior == EstadoReady && nuevoEstado == EstadoExec:
 		pcb.InicioUltimaRafaga = horaActual
 		pcb.HoraEjecucion = horaActual
+		if pcb.HoraPrimeraEjecucion.IsZero() {
+			pcb.HoraPrimeraEjecucion = horaActual
+		}
+		pcb.Prioridad = pcb.PrioridadBase // El aging solo vale mientras espera
 
 	case estadoAnterior == EstadoExec:
 		if !pcb.InicioUltimaRafaga.IsZero() {
@@ -96,19 +175,35 @@ func (pcb *PCB) CambiarEstado(nuevoEstado string) {
 			pcb.TotalEjecuciones++
 			pcb.TotalTiempoEjecucion += pcb.UltimaRafagaReal
 			pcb.actualizarEstimacion()
+			registrarRafagaEnSistema(pcb)
+			registrarOcupacionCPU(pcb.CPUAsignada, pcb.UltimaRafagaReal)
 		}
+		conPlanificadorSTS(func(p Planificador) { p.AlFinalizarRafaga(pcb, nuevoEstado) })
 	}
 
 	// Actualizar timestamps
 	switch nuevoEstado {
 	case EstadoReady:
 		pcb.HoraListo = horaActual
+		pcb.UltimoAging = horaActual
 	case EstadoBlocked:
 		pcb.HoraBloqueo = horaActual
 	case EstadoExit:
 		pcb.HoraFinalizacion = horaActual
 	}
 
+	recurso := ""
+	if estadoAnterior == EstadoExec || nuevoEstado == EstadoExec {
+		recurso = pcb.CPUAsignada
+	}
+	registrarEventoTraza(EventoTraza{Marca: horaActual, Tipo: EventoEstado, PID: pcb.PID, Desde: estadoAnterior, Hacia: nuevoEstado, Recurso: recurso})
+
+	pcb.metricasMutex.Lock()
+	pcb.TiempoEstados[estadoAnterior] += horaActual.Sub(pcb.HoraEntradaEstado).Seconds() * 1000
+	pcb.ContadorEstados[nuevoEstado]++
+	pcb.metricasMutex.Unlock()
+	pcb.HoraEntradaEstado = horaActual
+
 	pcb.Estado = nuevoEstado
 	utils.InfoLog.Info(fmt.Sprintf("(%d) - Pasa del estado %s al estado %s", pcb.PID, estadoAnterior, nuevoEstado))
 }
@@ -119,7 +214,7 @@ func (pcb *PCB) actualizarEstimacion() {
 		return
 	}
 
-	alpha := kernelConfig.Alpha
+	alpha := configKernel().Alpha
 	if alpha < 0 || alpha > 1 {
 		alpha = 0.5
 	}
@@ -127,30 +222,85 @@ func (pcb *PCB) actualizarEstimacion() {
 	pcb.EstimacionSiguienteRafaga = alpha*pcb.UltimaRafagaReal + (1-alpha)*pcb.EstimacionSiguienteRafaga
 }
 
+// AsignarPrioridad fija la prioridad base del proceso. Valores negativos se ignoran
+func (pcb *PCB) AsignarPrioridad(prioridad int) {
+	if prioridad < 0 {
+		return
+	}
+	pcb.PrioridadBase = prioridad
+	pcb.Prioridad = prioridad
+}
+
 func (pcb *PCB) String() string {
 	return fmt.Sprintf("PCB{PID: %d, Estado: %s, Tamaño: %d, PC: %d}",
 		pcb.PID, pcb.Estado, pcb.Tamanio, pcb.PC)
 }
 
-// CalcularMetricas optimizado
+// CalcularMetricas informa, al finalizar, la cantidad de veces y el tiempo total (segundos) en cada estado,
+// y los tiempos de respuesta, retorno y espera derivados de esos totales
 func (pcb *PCB) CalcularMetricas() {
-	tiempoNew := 0.0
-	if pcb.HoraListo.After(pcb.HoraCreacion) {
-		tiempoNew = pcb.HoraListo.Sub(pcb.HoraCreacion).Seconds()
+	metricas := []string{}
+	pcb.metricasMutex.Lock()
+	for _, estado := range estadosMetricas {
+		// Los estados de suspensión solo se informan si el proceso pasó por ellos
+		esSuspension := estado == EstadoSuspBlocked || estado == EstadoSuspReady
+		if esSuspension && pcb.ContadorEstados[estado] == 0 {
+			continue
+		}
+		// Mismo formato que el log original: tiempo en segundos con dos decimales
+		metricas = append(metricas, fmt.Sprintf("%s (%d)(%.2f)", estado, pcb.ContadorEstados[estado], pcb.TiempoEstados[estado]/1000))
 	}
+	pcb.metricasMutex.Unlock()
 
-	tiempoReady := 0.0
-	if pcb.HoraEjecucion.After(pcb.HoraListo) {
-		tiempoReady = pcb.HoraEjecucion.Sub(pcb.HoraListo).Seconds()
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - Métricas de estado: %s", pcb.PID, strings.Join(metricas, ", ")))
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - Métricas de tiempo: respuesta (%.2f), retorno (%.2f), espera (%.2f)",
+		pcb.PID, segundos(pcb.TiempoRespuesta()), pcb.TiempoRetorno()/1000, pcb.TiempoEspera()/1000))
+}
+
+// segundos pasa a segundos un tiempo en ms, conservando el -1 de "no ocurrió"
+func segundos(ms float64) float64 {
+	if ms < 0 {
+		return ms
 	}
+	return ms / 1000
+}
 
-	tiempoExec := pcb.TotalTiempoEjecucion / 1000.0
+// TiempoRespuesta devuelve los ms desde la creación hasta la primera ejecución (-1 si nunca ejecutó)
+func (pcb *PCB) TiempoRespuesta() float64 {
+	if pcb.HoraPrimeraEjecucion.IsZero() {
+		return -1
+	}
+	return pcb.HoraPrimeraEjecucion.Sub(pcb.HoraCreacion).Seconds() * 1000
+}
 
-	tiempoBlocked := 0.0
-	if !pcb.HoraBloqueo.IsZero() && !pcb.HoraFinalizacion.IsZero() {
-		tiempoBlocked = pcb.HoraFinalizacion.Sub(pcb.HoraBloqueo).Seconds()
+// TiempoRetorno devuelve los ms desde la creación hasta la finalización (o hasta ahora si sigue vivo)
+func (pcb *PCB) TiempoRetorno() float64 {
+	fin := pcb.HoraFinalizacion
+	if fin.IsZero() {
+		fin = utils.Ahora()
 	}
+	return fin.Sub(pcb.HoraCreacion).Seconds() * 1000
+}
+
+// TiempoEspera devuelve los ms acumulados en READY y SUSP.READY, listos para ejecutar pero sin CPU
+func (pcb *PCB) TiempoEspera() float64 {
+	pcb.metricasMutex.Lock()
+	defer pcb.metricasMutex.Unlock()
+	return pcb.TiempoEstados[EstadoReady] + pcb.TiempoEstados[EstadoSuspReady]
+}
 
-	utils.InfoLog.Info(fmt.Sprintf("(%d) - Métricas de estado: NEW (%d)(%.2f), READY (%d)(%.2f), EXEC (%d)(%.2f), BLOCKED (%d)(%.2f)",
-		pcb.PID, 1, tiempoNew, 1, tiempoReady, pcb.TotalEjecuciones, tiempoExec, 1, tiempoBlocked))
+// metricasEstado devuelve una copia de los contadores y tiempos por estado
+func (pcb *PCB) metricasEstado() (map[string]int, map[string]float64) {
+	pcb.metricasMutex.Lock()
+	defer pcb.metricasMutex.Unlock()
+
+	contador := make(map[string]int, len(pcb.ContadorEstados))
+	for estado, veces := range pcb.ContadorEstados {
+		contador[estado] = veces
+	}
+	tiempos := make(map[string]float64, len(pcb.TiempoEstados))
+	for estado, ms := range pcb.TiempoEstados {
+		tiempos[estado] = ms
+	}
+	return contador, tiempos
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -21,6 +22,9 @@ var (
 	colaSuspBlocked []*PCB          = []*PCB{}
 	colaExit        []*PCB          = []*PCB{}
 
+	// Cola auxiliar de VRR: procesos que vuelven de IO con quantum restante
+	colaReadyPrioritaria []*PCB = []*PCB{}
+
 	// Mutexes
 	newMutex         sync.Mutex
 	readyMutex       sync.Mutex
@@ -38,8 +42,13 @@ var (
 	mapaPCBs               map[int]*PCB = make(map[int]*PCB)
 	gradoMultiprogramacion int
 	semaforoMultiprogram   *utils.Semaforo
-	timersSuspension       map[int]*time.Timer
+	timersSuspension       map[int]utils.Temporizador
 	timersMutex            sync.Mutex
+
+	// Pausa de la planificación desde la consola: los planificadores no despachan ni admiten procesos
+	planificacionDetenida bool
+	pausaMutex            sync.Mutex
+	condPausa             = sync.NewCond(&pausaMutex)
 )
 
 // InicializarPlanificador optimizado
@@ -52,7 +61,12 @@ func InicializarPlanificador(config *KernelConfig) {
 
 	condNew = sync.NewCond(&newMutex)
 	condReady = sync.NewCond(&readyMutex)
-	timersSuspension = make(map[int]*time.Timer)
+	timersSuspension = make(map[int]utils.Temporizador)
+
+	inicializarSorteo(config.Seed)
+	planificadorSTS = crearPlanificador(TipoCortoPlazo, config.SchedulerAlgorithm)
+	planificadorLTS = crearPlanificador(TipoLargoPlazo, config.ReadyIngressAlgorithm)
+	inicializarRecursos(config)
 
 	utils.InfoLog.Info("Planificador inicializado",
 		"algoritmo_sts", config.SchedulerAlgorithm,
@@ -60,6 +74,41 @@ func InicializarPlanificador(config *KernelConfig) {
 		"multiprogramacion", gradoMultiprogramacion)
 }
 
+// detenerPlanificacion pausa el despacho y la admisión. Los procesos en CPU terminan su ráfaga actual
+func detenerPlanificacion() {
+	pausaMutex.Lock()
+	planificacionDetenida = true
+	pausaMutex.Unlock()
+	utils.InfoLog.Info("Planificación detenida")
+}
+
+// reanudarPlanificacion libera a los planificadores pausados
+func reanudarPlanificacion() {
+	pausaMutex.Lock()
+	planificacionDetenida = false
+	pausaMutex.Unlock()
+	condPausa.Broadcast()
+	condReady.Broadcast()
+	condNew.Broadcast()
+	utils.InfoLog.Info("Planificación reanudada")
+}
+
+// planificacionEstaDetenida indica si la planificación está pausada desde la consola
+func planificacionEstaDetenida() bool {
+	pausaMutex.Lock()
+	defer pausaMutex.Unlock()
+	return planificacionDetenida
+}
+
+// esperarSiDetenida bloquea al planificador que la llama mientras la planificación esté pausada
+func esperarSiDetenida() {
+	pausaMutex.Lock()
+	defer pausaMutex.Unlock()
+	for planificacionDetenida {
+		condPausa.Wait()
+	}
+}
+
 // GenerarNuevoPID devuelve un PID único
 func GenerarNuevoPID() int {
 	pidMutex.Lock()
@@ -76,6 +125,14 @@ func BuscarPCBPorPID(pid int) *PCB {
 	return mapaPCBs[pid]
 }
 
+// procesoFinalizado indica si el proceso ya terminó, por ejemplo porque lo mataron mientras la CPU respondía
+func procesoFinalizado(pcb *PCB) bool {
+	mapaMutex.RLock()
+	_, existe := mapaPCBs[pcb.PID]
+	mapaMutex.RUnlock()
+	return !existe || pcb.Estado == EstadoExit
+}
+
 // AgregarProcesoANew optimizado
 func AgregarProcesoANew(pcb *PCB) {
 	newMutex.Lock()
@@ -86,6 +143,11 @@ func AgregarProcesoANew(pcb *PCB) {
 
 // MoverProcesoAReady optimizado
 func MoverProcesoAReady(pcb *PCB) {
+	if procesoFinalizado(pcb) {
+		utils.InfoLog.Info("Proceso ya finalizado, no vuelve a READY", "pid", pcb.PID)
+		return
+	}
+
 	// Si el proceso está en SUSP.BLOCKED, debe ir a SUSP.READY primero
 	if pcb.Estado == EstadoSuspBlocked {
 		utils.InfoLog.Info(" Proceso en SUSP.BLOCKED, moviendo a SUSP.READY", "pid", pcb.PID)
@@ -104,20 +166,48 @@ func MoverProcesoAReady(pcb *PCB) {
 	// Cancelar timer de suspensión si existe (proceso terminó IO antes de ser suspendido)
 	timersMutex.Lock()
 	if timer, existe := timersSuspension[pcb.PID]; existe {
-		timer.Stop()
+		timer.Detener()
 		delete(timersSuspension, pcb.PID)
 		utils.InfoLog.Info(" Timer de suspensión cancelado - proceso terminó IO", "pid", pcb.PID)
 	}
 	timersMutex.Unlock()
 
 	pcb.CambiarEstado(EstadoReady)
+	agregarAReady(pcb)
+}
 
+// agregarAReady encola un proceso en la cola de READY que decida el algoritmo de corto plazo
+func agregarAReady(pcb *PCB) {
 	readyMutex.Lock()
-	colaReady = append(colaReady, pcb)
+	conPlanificadorSTS(func(p Planificador) { p.AlPasarAReady(pcb) })
 	readyMutex.Unlock()
 	condReady.Signal()
 }
 
+// hayProcesosListos indica si hay procesos en alguna cola de READY. Requiere readyMutex tomado
+func hayProcesosListos() bool {
+	return cantidadProcesosListos() > 0
+}
+
+// cantidadProcesosListos cuenta los procesos de todas las colas de READY. Requiere readyMutex tomado
+func cantidadProcesosListos() int {
+	cantidad := len(colaReady) + len(colaReadyPrioritaria)
+	for _, cola := range colasMLFQ {
+		cantidad += len(cola)
+	}
+	return cantidad
+}
+
+// quitarDeListos remueve un proceso de cualquier cola de READY. Requiere readyMutex tomado
+func quitarDeListos(pcb *PCB) bool {
+	for nivel := range colasMLFQ {
+		if removerDeCola(&colasMLFQ[nivel], pcb) {
+			return true
+		}
+	}
+	return removerDeCola(&colaReadyPrioritaria, pcb) || removerDeCola(&colaReady, pcb)
+}
+
 // MoverProcesoASuspReady mueve un proceso de SUSP.BLOCKED a SUSP.READY
 func MoverProcesoASuspReady(pcb *PCB) {
 	// Remover de SUSP.BLOCKED
@@ -129,7 +219,7 @@ func MoverProcesoASuspReady(pcb *PCB) {
 	// Cancelar timer de suspensión si existe
 	timersMutex.Lock()
 	if timer, existe := timersSuspension[pcb.PID]; existe {
-		timer.Stop()
+		timer.Detener()
 		delete(timersSuspension, pcb.PID)
 	}
 	timersMutex.Unlock()
@@ -149,8 +239,18 @@ func MoverProcesoASuspReady(pcb *PCB) {
 	utils.InfoLog.Info("Proceso movido de SUSP.BLOCKED -> SUSP.READY", "pid", pcb.PID)
 }
 
+// esBloqueoPorIO indica si el motivo de bloqueo corresponde a una IO (dispositivo o DUMP_MEMORY)
+func esBloqueoPorIO(motivo string) bool {
+	return strings.HasPrefix(motivo, "IO_") || motivo == "DUMP_MEMORY"
+}
+
 // MoverProcesoABlocked optimizado
 func MoverProcesoABlocked(pcb *PCB, motivo string) {
+	if procesoFinalizado(pcb) {
+		utils.InfoLog.Info("Proceso ya finalizado, no se bloquea", "pid", pcb.PID, "motivo", motivo)
+		return
+	}
+
 	execMutex.Lock()
 	for cpu, pcbEnExec := range colaExec {
 		if pcbEnExec != nil && pcbEnExec.PID == pcb.PID {
@@ -162,9 +262,11 @@ func MoverProcesoABlocked(pcb *PCB, motivo string) {
 
 	pcb.MotivoBloqueo = motivo
 	pcb.CambiarEstado(EstadoBlocked)
+	detenerTimerQuantum(pcb)
+	conPlanificadorSTS(func(p Planificador) { p.AlBloquearse(pcb, motivo) })
 
 	// Log específico para bloqueo por IO
-	if motivo != "" && (motivo[:3] == "IO_" || motivo == "DUMP_MEMORY") {
+	if esBloqueoPorIO(motivo) {
 		dispositivoNombre := motivo
 		if motivo[:3] == "IO_" {
 			dispositivoNombre = motivo[3:] // Remover "IO_" del prefijo
@@ -178,12 +280,31 @@ func MoverProcesoABlocked(pcb *PCB, motivo string) {
 	colaBlocked = append(colaBlocked, pcb)
 	blockedMutex.Unlock()
 
-	go iniciarTimerSuspension(pcb)
+	// Con presión de memoria la suspensión la decide el LTS cuando un proceso no entra.
+	// Los hilos secundarios no se suspenden: la memoria es del proceso
+	if !suspensionPorPresion() && !pcb.esHiloSecundario() {
+		go iniciarTimerSuspension(pcb)
+	}
+}
+
+// desbloquearProceso pasa a READY (o a SUSP.READY si fue suspendido) un proceso cuya espera terminó
+func desbloquearProceso(pcb *PCB, evento string) {
+	switch pcb.Estado {
+	case EstadoBlocked:
+		utils.InfoLog.Info(fmt.Sprintf("(%d) - Finalizó %s y pasa a READY", pcb.PID, evento))
+		MoverProcesoAReady(pcb)
+		go despacharProcesoSiCorresponde()
+	case EstadoSuspBlocked:
+		utils.InfoLog.Info(fmt.Sprintf("(%d) - Finalizó %s y pasa a SUSP.READY", pcb.PID, evento))
+		MoverProcesoASuspReady(pcb)
+	default:
+		utils.InfoLog.Warn("Proceso en estado inesperado al desbloquearse", "pid", pcb.PID, "estado", pcb.Estado, "evento", evento)
+	}
 }
 
 // iniciarTimerSuspension con log de inicio
 func iniciarTimerSuspension(pcb *PCB) {
-	tiempoSuspension := time.Duration(kernelConfig.SuspensionTime) * time.Millisecond
+	tiempoSuspension := time.Duration(configKernel().SuspensionTime) * time.Millisecond
 	if tiempoSuspension <= 0 {
 		tiempoSuspension = 4500 * time.Millisecond
 	}
@@ -193,36 +314,40 @@ func iniciarTimerSuspension(pcb *PCB) {
 
 	timersMutex.Lock()
 	if timer, existe := timersSuspension[pcb.PID]; existe {
-		timer.Stop()
+		timer.Detener()
 	}
 
-	timer := time.AfterFunc(tiempoSuspension, func() {
+	timer := utils.Programar(tiempoSuspension, func() {
 		suspenderProceso(pcb.PID)
 	})
 	timersSuspension[pcb.PID] = timer
 	timersMutex.Unlock()
 }
 
-// suspenderProceso con log de notificación a Memoria
-func suspenderProceso(pid int) {
+// suspenderProceso pasa un proceso de BLOCKED a SUSP.BLOCKED y lo envía a SWAP. Devuelve si se suspendió
+func suspenderProceso(pid int) bool {
 	pcb := BuscarPCBPorPID(pid)
 	if pcb == nil {
 		utils.InfoLog.Warn("Proceso no encontrado para suspensión", "pid", pid)
-		return
+		return false
 	}
 
 	if pcb.Estado != EstadoBlocked {
 		utils.InfoLog.Warn("Proceso no válido para suspensión", "pid", pid, "estado_actual", pcb.Estado)
-		return
+		return false
+	}
+
+	if pcb.esHiloSecundario() || tieneHilosVivos(pcb) {
+		utils.InfoLog.Info("Proceso con hilos en ejecución, no se suspende", "pid", pid, "proceso", pcb.PIDMemoria())
+		return false
 	}
 
 	if !removerDeBlocked(pcb) {
 		utils.InfoLog.Warn("No se pudo remover proceso de BLOCKED", "pid", pid)
-		return
+		return false
 	}
 
-	// Log para saber que el timer se disparó
-	utils.InfoLog.Info("Timer de suspensión finalizado. Suspendiendo proceso.", "pid", pcb.PID)
+	utils.InfoLog.Info("Suspendiendo proceso", "pid", pcb.PID, "algoritmo_mediano_plazo", algoritmoMedianoPlazo())
 
 	pcb.CambiarEstado(EstadoSuspBlocked)
 	pcb.EnSwap = true // Marcar que el proceso estará en SWAP
@@ -231,8 +356,64 @@ func suspenderProceso(pid int) {
 	colaSuspBlocked = append(colaSuspBlocked, pcb)
 	suspBlockedMutex.Unlock()
 
-	go notificarSwapAMemoria(pcb.PID) // La función notificarSwapAMemoria ya la tienes bien.
+	// Sincrónico: con presión de memoria el LTS vuelve a consultar el espacio libre al terminar
+	notificarSwapAMemoria(pcb.PID)
 	semaforoMultiprogram.Signal()
+	notificarMemoriaLiberada()
+	registrarSuspension()
+	return true
+}
+
+// reanudarProcesoSuspendido trae a READY un proceso de SUSP.READY sin esperar al LTS,
+// siempre que haya lugar en el grado de multiprogramación
+func reanudarProcesoSuspendido(pcb *PCB) error {
+	if pcb.Estado != EstadoSuspReady {
+		return fmt.Errorf("el proceso %d está en %s y solo se puede reanudar desde %s", pcb.PID, pcb.Estado, EstadoSuspReady)
+	}
+
+	if !semaforoMultiprogram.TryWait() {
+		return fmt.Errorf("grado de multiprogramación completo")
+	}
+
+	if !removerDeSuspReady(pcb) {
+		semaforoMultiprogram.Signal()
+		return fmt.Errorf("el proceso %d ya no está en %s", pcb.PID, EstadoSuspReady)
+	}
+
+	if pcb.EnSwap && !notificarDesswapAMemoria(pcb.PID) {
+		suspReadyMutex.Lock()
+		colaSuspReady = append(colaSuspReady, pcb)
+		suspReadyMutex.Unlock()
+		semaforoMultiprogram.Signal()
+		return fmt.Errorf("memoria no pudo cargar el proceso %d desde SWAP", pcb.PID)
+	}
+
+	pcb.EnSwap = false
+	pcb.CambiarEstado(EstadoReady)
+	agregarAReady(pcb)
+	utils.InfoLog.Info("Proceso reanudado de SUSP.READY a READY", "pid", pcb.PID)
+	return nil
+}
+
+// cancelarTimerSuspension detiene el timer de suspensión pendiente del proceso
+func cancelarTimerSuspension(pid int) {
+	timersMutex.Lock()
+	defer timersMutex.Unlock()
+
+	if timer, existe := timersSuspension[pid]; existe {
+		timer.Detener()
+		delete(timersSuspension, pid)
+	}
+}
+
+// matarProceso finaliza un proceso a pedido externo. Si está ejecutando, primero se interrumpe su CPU;
+// la respuesta que ya estaba en camino se descarta al ver el proceso en EXIT y recién ahí se libera la CPU
+func matarProceso(pcb *PCB, motivo string) {
+	if pcb.Estado == EstadoExec {
+		desalojarProcesoActual(pcb)
+	}
+	detenerTimerQuantum(pcb)
+	FinalizarProceso(pcb, motivo)
 }
 
 // FinalizarProceso optimizado
@@ -244,12 +425,17 @@ func FinalizarProceso(pcb *PCB, motivo string) {
 	}
 	mapaMutex.Unlock()
 
+	// Al terminar el hilo principal terminan también los hilos secundarios del proceso
+	if !pcb.esHiloSecundario() {
+		finalizarHilosDelProceso(pcb)
+	}
+
 	estadoPrevio := pcb.Estado
 
 	// Limpiar timer
 	timersMutex.Lock()
 	if timer, existe := timersSuspension[pcb.PID]; existe {
-		timer.Stop()
+		timer.Detener()
 		delete(timersSuspension, pcb.PID)
 	}
 	timersMutex.Unlock()
@@ -258,15 +444,8 @@ func FinalizarProceso(pcb *PCB, motivo string) {
 	fueRemovido := false
 	switch estadoPrevio {
 	case EstadoExec:
-		execMutex.Lock()
-		for cpu, pcbEnExec := range colaExec {
-			if pcbEnExec != nil && pcbEnExec.PID == pcb.PID {
-				delete(colaExec, cpu)
-				fueRemovido = true
-				break
-			}
-		}
-		execMutex.Unlock()
+		// La CPU sigue ocupada hasta que responda: despacharYProcesarCPU la libera al ver el proceso en EXIT
+		fueRemovido = true
 	case EstadoReady:
 		fueRemovido = removerDeReady(pcb)
 	case EstadoBlocked:
@@ -281,22 +460,35 @@ func FinalizarProceso(pcb *PCB, motivo string) {
 		return
 	}
 
+	pcb.MotivoFinalizacion = motivo
 	pcb.CambiarEstado(EstadoExit)
 
 	exitMutex.Lock()
 	colaExit = append(colaExit, pcb)
 	exitMutex.Unlock()
 
-	// Liberar multiprogramación
-	if estadoPrevio == EstadoReady || estadoPrevio == EstadoExec || estadoPrevio == EstadoBlocked {
-		semaforoMultiprogram.Signal()
+	// Liberar multiprogramación (los hilos secundarios no la ocupan)
+	if pcb.esHiloSecundario() {
+		go notificarFinHiloAMemoria(pcb.PIDMemoria(), pcb.TID)
+	} else {
+		if estadoPrevio == EstadoReady || estadoPrevio == EstadoExec || estadoPrevio == EstadoBlocked {
+			semaforoMultiprogram.Signal()
+		}
+		go notificarFinalizacionAMemoria(pcb.PID)
 	}
 
-	go notificarFinalizacionAMemoria(pcb.PID)
-
 	if estadoPrevio != EstadoExit {
 		utils.InfoLog.Info(fmt.Sprintf("(%d) - Finaliza el proceso", pcb.PID))
 		utils.InfoLog.Info("Proceso finalizado", "pid", pcb.PID, "motivo", motivo)
+		if _, esMLFQ := planificadorCortoPlazo().(*planificadorMLFQ); esMLFQ {
+			utils.InfoLog.Info(fmt.Sprintf("(%d) - Finaliza en nivel MLFQ %d", pcb.PID, pcb.NivelMLFQ))
+		}
+		if usaTickets() {
+			reportarParticipacionCPU(pcb)
+		}
+		if pcb.TiempoReal {
+			reportarTiempoReal(pcb)
+		}
 		pcb.CalcularMetricas()
 	}
 
@@ -304,6 +496,12 @@ func FinalizarProceso(pcb *PCB, motivo string) {
 	delete(mapaPCBs, pcb.PID)
 	mapaMutex.Unlock()
 
+	liberarRecursosDeProceso(pcb)
+	liberarIPCDeProceso(pcb)
+	descartarPeticionesIO(pcb.PID)
+	notificarFinAlPadre(pcb)
+	notificarFinHilo(pcb)
+
 	// Usar variable para evitar warning del compilador
 	_ = fueRemovido
 }
@@ -323,14 +521,16 @@ func notificarFinalizacionAMemoria(pid int) {
 	_, err := cliente.EnviarHTTPMensaje(utils.MensajeFinalizarProceso, "default", datos)
 	if err != nil {
 		utils.ErrorLog.Error("Error notificando finalización a Memoria", "pid", pid, "error", err.Error())
+		return
 	}
+	notificarMemoriaLiberada()
 }
 
 // Funciones auxiliares optimizadas
 func removerDeReady(pcb *PCB) bool {
 	readyMutex.Lock()
 	defer readyMutex.Unlock()
-	return removerDeCola(&colaReady, pcb)
+	return quitarDeListos(pcb)
 }
 
 func removerDeBlocked(pcb *PCB) bool {
@@ -393,5 +593,6 @@ func notificarSwapAMemoria(pid int) {
 	datos := map[string]interface{}{
 		"pid": pid,
 	}
+	trazarEvento(EventoSwapSalida, pid, "MEMORIA", "")
 	cliente.EnviarHTTPMensaje(utils.MensajeSuspenderProceso, "default", datos)
 }
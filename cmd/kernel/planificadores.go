@@ -0,0 +1,116 @@
+package main
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+// Tipos de planificador que admite el registro
+const (
+	TipoCortoPlazo = "CORTO_PLAZO"
+	TipoLargoPlazo = "LARGO_PLAZO"
+)
+
+// Planificador define los puntos de extensión de un algoritmo de planificación.
+// Los algoritmos de corto plazo trabajan sobre las colas de READY y los de largo plazo sobre colaNew
+type Planificador interface {
+	// SeleccionarSiguiente elige, sin retirarlo de su cola, el próximo proceso a despachar o admitir
+	SeleccionarSiguiente() *PCB
+	// DebeDesalojar devuelve el proceso en ejecución que debe ceder la CPU al candidato, o nil
+	DebeDesalojar(candidato *PCB, enEjecucion []*PCB) *PCB
+	// AlBloquearse se invoca cuando un proceso pasa a BLOCKED por el motivo indicado
+	AlBloquearse(pcb *PCB, motivo string)
+	// AlPasarAReady encola el proceso en READY. Se invoca con readyMutex tomado
+	AlPasarAReady(pcb *PCB)
+	// AlFinalizarRafaga se invoca cuando un proceso deja EXEC hacia nuevoEstado
+	AlFinalizarRafaga(pcb *PCB, nuevoEstado string)
+}
+
+// planificadorConQuantum lo implementan los algoritmos que desalojan por fin de quantum
+type planificadorConQuantum interface {
+	QuantumPara(pcb *PCB) float64
+}
+
+// planificadorConSegundoPlano lo implementan los algoritmos con una rutina periódica propia
+type planificadorConSegundoPlano interface {
+	IniciarSegundoPlano()
+}
+
+// planificadorConAdmision lo implementan los algoritmos que validan un proceso antes de que el LTS lo admita
+type planificadorConAdmision interface {
+	Admitir(pcb *PCB) bool
+}
+
+// planificadorBase provee el comportamiento por defecto de los hooks (FIFO sin desalojo)
+type planificadorBase struct{}
+
+func (planificadorBase) DebeDesalojar(candidato *PCB, enEjecucion []*PCB) *PCB { return nil }
+
+func (planificadorBase) AlBloquearse(pcb *PCB, motivo string) {}
+
+func (planificadorBase) AlPasarAReady(pcb *PCB) {
+	colaReady = append(colaReady, pcb)
+}
+
+func (planificadorBase) AlFinalizarRafaga(pcb *PCB, nuevoEstado string) {}
+
+var (
+	registroPlanificadores = map[string]map[string]func() Planificador{
+		TipoCortoPlazo: {},
+		TipoLargoPlazo: {},
+	}
+
+	planificadorSTS Planificador // Se reemplaza al recargar la configuración; protegido por stsMutex
+	planificadorLTS Planificador
+	stsMutex        sync.RWMutex
+)
+
+// planificadorCortoPlazo devuelve el algoritmo de corto plazo vigente
+func planificadorCortoPlazo() Planificador {
+	stsMutex.RLock()
+	defer stsMutex.RUnlock()
+	return planificadorSTS
+}
+
+// conPlanificadorSTS ejecuta un hook del algoritmo de corto plazo sin que lo reemplacen a mitad de camino:
+// la recarga espera a que termine y recién después migra las colas al algoritmo nuevo
+func conPlanificadorSTS(hook func(Planificador)) {
+	stsMutex.RLock()
+	defer stsMutex.RUnlock()
+	hook(planificadorSTS)
+}
+
+// RegistrarPlanificador agrega un algoritmo al registro para que pueda elegirse por configuración
+func RegistrarPlanificador(tipo string, nombre string, fabrica func() Planificador) {
+	registro, existe := registroPlanificadores[tipo]
+	if !existe {
+		panic("tipo de planificador desconocido: " + tipo)
+	}
+	if _, duplicado := registro[nombre]; duplicado {
+		panic("planificador registrado dos veces: " + tipo + "/" + nombre)
+	}
+	registro[nombre] = fabrica
+}
+
+// crearPlanificador instancia el algoritmo registrado con ese nombre. Si no existe usa FIFO
+func crearPlanificador(tipo string, nombre string) Planificador {
+	registro := registroPlanificadores[tipo]
+	fabrica, existe := registro[nombre]
+	if !existe {
+		utils.InfoLog.Warn("Algoritmo no reconocido, usando FIFO", "tipo", tipo, "algoritmo", nombre, "disponibles", planificadoresRegistrados(tipo))
+		fabrica = registro["FIFO"]
+	}
+	return fabrica()
+}
+
+// planificadoresRegistrados devuelve los nombres registrados para un tipo, ordenados
+func planificadoresRegistrados(tipo string) []string {
+	nombres := make([]string, 0, len(registroPlanificadores[tipo]))
+	for nombre := range registroPlanificadores[tipo] {
+		nombres = append(nombres, nombre)
+	}
+	sort.Strings(nombres)
+	return nombres
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+const ticketsPorDefectoSinConfig = 100
+
+var (
+	// tiempoCPUTotal acumula los milisegundos de CPU consumidos por todos los procesos
+	tiempoCPUTotal float64
+	cpuTotalMutex  sync.Mutex
+
+	// sorteo genera los boletos de LOTTERY. Se usa con readyMutex tomado
+	sorteo *rand.Rand
+)
+
+func init() {
+	RegistrarPlanificador(TipoCortoPlazo, "LOTTERY", func() Planificador { return &planificadorLottery{} })
+	RegistrarPlanificador(TipoCortoPlazo, "STRIDE", func() Planificador { return &planificadorStride{} })
+}
+
+// inicializarSorteo crea el generador de LOTTERY con la SEMILLA configurada. Sin SEMILLA se elige una
+// al azar; se loguea siempre para poder repetir la corrida. Requiere readyMutex tomado si ya hay planificación
+func inicializarSorteo(semilla int64) {
+	if semilla == 0 {
+		semilla = time.Now().UnixNano()
+	}
+	sorteo = rand.New(rand.NewSource(semilla))
+	utils.InfoLog.Info("Semilla del sorteo de LOTTERY", "semilla", semilla)
+}
+
+// ticketsPorDefecto devuelve la cantidad de tickets configurada para los procesos nuevos
+func ticketsPorDefecto() int {
+	if configKernel() == nil || configKernel().DefaultTickets <= 0 {
+		return ticketsPorDefectoSinConfig
+	}
+	return configKernel().DefaultTickets
+}
+
+// asignarTickets aplica la opción TICKETS=N de la creación del proceso. Sin la opción conserva los que ya tenía
+func asignarTickets(pcb *PCB, opciones map[string]interface{}) {
+	tickets, indicado := opciones["TICKETS"].(float64)
+	if !indicado {
+		return
+	}
+	if tickets <= 0 {
+		utils.InfoLog.Warn("Cantidad de tickets inválida, se conservan los que tenía", "pid", pcb.PID, "tickets", tickets)
+		return
+	}
+
+	pcb.Tickets = int(tickets)
+	utils.InfoLog.Info("Tickets asignados", "pid", pcb.PID, "tickets", pcb.Tickets)
+}
+
+// ticketsEfectivos evita divisiones por cero con procesos sin tickets
+func ticketsEfectivos(pcb *PCB) int {
+	if pcb.Tickets <= 0 {
+		return 1
+	}
+	return pcb.Tickets
+}
+
+// usaTickets indica si el algoritmo de corto plazo reparte la CPU según los tickets
+func usaTickets() bool {
+	switch planificadorCortoPlazo().(type) {
+	case *planificadorLottery, *planificadorStride:
+		return true
+	}
+	return false
+}
+
+// tiempoCPUSistema devuelve el tiempo de CPU acumulado por todos los procesos
+func tiempoCPUSistema() float64 {
+	cpuTotalMutex.Lock()
+	defer cpuTotalMutex.Unlock()
+	return tiempoCPUTotal
+}
+
+// registrarRafagaEnSistema suma la ráfaga al total del sistema y, con planificación proporcional,
+// reparte su duración entre los procesos que competían por la CPU según sus tickets
+func registrarRafagaEnSistema(pcb *PCB) {
+	cpuTotalMutex.Lock()
+	tiempoCPUTotal += pcb.UltimaRafagaReal
+	cpuTotalMutex.Unlock()
+
+	if !usaTickets() {
+		return
+	}
+
+	mapaMutex.RLock()
+	defer mapaMutex.RUnlock()
+
+	competidores := []*PCB{}
+	totalTickets := 0
+	for _, p := range mapaPCBs {
+		if p.Estado == EstadoReady || p.Estado == EstadoExec {
+			competidores = append(competidores, p)
+			totalTickets += ticketsEfectivos(p)
+		}
+	}
+
+	for _, p := range competidores {
+		p.CPUEsperadoPorCuota += pcb.UltimaRafagaReal * float64(ticketsEfectivos(p)) / float64(totalTickets)
+	}
+}
+
+// reportarParticipacionCPU informa la porción de CPU obtenida frente a la que le correspondía por tickets
+func reportarParticipacionCPU(pcb *PCB) {
+	cpuDuranteVida := tiempoCPUSistema() - pcb.CPUSistemaAlCrear
+	if cpuDuranteVida <= 0 {
+		utils.InfoLog.Info("Proceso finalizado sin consumo de CPU en el sistema", "pid", pcb.PID, "tickets", pcb.Tickets)
+		return
+	}
+
+	obtenida := pcb.TotalTiempoEjecucion / cpuDuranteVida * 100
+	configurada := pcb.CPUEsperadoPorCuota / cpuDuranteVida * 100
+
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - Participación de CPU: obtenida %.2f%% - configurada %.2f%% (%d tickets)",
+		pcb.PID, obtenida, configurada, pcb.Tickets))
+	utils.InfoLog.Info("Participación de CPU",
+		"pid", pcb.PID,
+		"tickets", pcb.Tickets,
+		"cpu_proceso_ms", pcb.TotalTiempoEjecucion,
+		"cpu_esperado_ms", pcb.CPUEsperadoPorCuota,
+		"cpu_sistema_ms", cpuDuranteVida)
+}
+
+// planificadorLottery sortea la CPU entre los procesos en READY en proporción a sus tickets
+type planificadorLottery struct {
+	planificadorRR
+}
+
+func (p *planificadorLottery) SeleccionarSiguiente() *PCB {
+	if len(colaReady) == 0 {
+		return nil
+	}
+
+	totalTickets := 0
+	for _, pcb := range colaReady {
+		totalTickets += ticketsEfectivos(pcb)
+	}
+
+	boleto := sorteo.Intn(totalTickets)
+	for _, pcb := range colaReady {
+		boleto -= ticketsEfectivos(pcb)
+		if boleto < 0 {
+			utils.InfoLog.Info("LOTTERY seleccionó proceso", "pid", pcb.PID, "tickets", pcb.Tickets, "tickets_en_juego", totalTickets)
+			return pcb
+		}
+	}
+	return colaReady[len(colaReady)-1]
+}
+
+// planificadorStride ejecuta siempre al proceso de menor pase; el pase avanza en proporción
+// inversa a los tickets según la CPU realmente consumida
+type planificadorStride struct {
+	planificadorRR
+	paseGlobal float64
+}
+
+func (p *planificadorStride) SeleccionarSiguiente() *PCB {
+	if len(colaReady) == 0 {
+		return nil
+	}
+
+	seleccionado := colaReady[0]
+	for _, pcb := range colaReady[1:] {
+		if pcb.Pase < seleccionado.Pase {
+			seleccionado = pcb
+		}
+	}
+
+	p.paseGlobal = seleccionado.Pase
+	utils.InfoLog.Info("STRIDE seleccionó proceso", "pid", seleccionado.PID, "pase", seleccionado.Pase, "tickets", seleccionado.Tickets)
+	return seleccionado
+}
+
+// AlPasarAReady impide que un proceso nuevo o que estuvo bloqueado acumule crédito de CPU
+func (p *planificadorStride) AlPasarAReady(pcb *PCB) {
+	if pcb.Pase < p.paseGlobal {
+		pcb.Pase = p.paseGlobal
+	}
+	colaReady = append(colaReady, pcb)
+}
+
+func (p *planificadorStride) AlFinalizarRafaga(pcb *PCB, nuevoEstado string) {
+	pcb.Pase += pcb.UltimaRafagaReal / float64(ticketsEfectivos(pcb))
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+const quantumPorDefecto = 2000.0
+
+var (
+	timersQuantum map[int]utils.Temporizador = make(map[int]utils.Temporizador)
+	quantumMutex  sync.Mutex
+)
+
+// iniciarTimerQuantum arma el timer de quantum para el despacho actual del proceso
+func iniciarTimerQuantum(pcb *PCB, quantum float64) {
+	pcb.QuantumAsignado = quantum
+	pcb.QuantumRestante = 0
+	pcb.QuantumAgotado = false
+
+	utils.InfoLog.Info("Iniciado timer de quantum", "pid", pcb.PID, "quantum_ms", quantum)
+
+	quantumMutex.Lock()
+	if timer, existe := timersQuantum[pcb.PID]; existe {
+		timer.Detener()
+	}
+	timersQuantum[pcb.PID] = utils.Programar(time.Duration(quantum)*time.Millisecond, func() {
+		finDeQuantum(pcb)
+	})
+	quantumMutex.Unlock()
+}
+
+// finDeQuantum se ejecuta cuando vence el quantum y envía la interrupción a la CPU
+func finDeQuantum(pcb *PCB) {
+	quantumMutex.Lock()
+	delete(timersQuantum, pcb.PID)
+	quantumMutex.Unlock()
+
+	if pcb.Estado != EstadoExec {
+		return
+	}
+
+	pcb.QuantumAgotado = true
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - Desalojado por fin de Quantum", pcb.PID))
+	desalojarProcesoActual(pcb)
+}
+
+// detenerTimerQuantum cancela el timer de quantum si el proceso deja la CPU antes de que venza
+func detenerTimerQuantum(pcb *PCB) {
+	quantumMutex.Lock()
+	defer quantumMutex.Unlock()
+
+	if timer, existe := timersQuantum[pcb.PID]; existe {
+		timer.Detener()
+		delete(timersQuantum, pcb.PID)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+var (
+	rutaConfiguracion string
+	recargaMutex      sync.Mutex
+)
+
+// escucharSIGHUP recarga la configuración cada vez que el proceso recibe SIGHUP
+func escucharSIGHUP() {
+	sigHup := make(chan os.Signal, 1)
+	signal.Notify(sigHup, syscall.SIGHUP)
+
+	go func() {
+		for range sigHup {
+			utils.InfoLog.Info("SIGHUP recibido, recargando configuración")
+			if _, err := recargarConfiguracion(); err != nil {
+				utils.ErrorLog.Error("No se pudo recargar la configuración", "error", err)
+			}
+		}
+	}()
+}
+
+// HandlerRecargarConfiguracion atiende el mensaje de administración que recarga la configuración
+func HandlerRecargarConfiguracion(msg *utils.Mensaje) (interface{}, error) {
+	utils.InfoLog.Info("Recarga de configuración solicitada", "origen", msg.Origen)
+
+	cambios, err := recargarConfiguracion()
+	if err != nil {
+		return map[string]interface{}{"status": "ERROR", "mensaje": err.Error()}, nil
+	}
+	return map[string]interface{}{"status": "OK", "cambios": cambios}, nil
+}
+
+// recargarConfiguracion relee el archivo de configuración y aplica los cambios a los planificadores en ejecución.
+// Las direcciones y puertos no se recargan porque requieren reiniciar las conexiones
+func recargarConfiguracion() ([]string, error) {
+	recargaMutex.Lock()
+	defer recargaMutex.Unlock()
+
+	nueva, err := utils.LeerConfiguracion[KernelConfig](rutaConfiguracion)
+	if err != nil {
+		return nil, err
+	}
+
+	anterior := configKernel()
+	nueva.IPKernel = anterior.IPKernel
+	nueva.PortKernel = anterior.PortKernel
+	nueva.IPMemory = anterior.IPMemory
+	nueva.PortMemory = anterior.PortMemory
+	nueva.LogLevel = anterior.LogLevel
+
+	cambios := camposModificados(anterior, nueva)
+	if len(cambios) == 0 {
+		utils.InfoLog.Info("Configuración recargada sin cambios", "ruta", rutaConfiguracion)
+		return cambios, nil
+	}
+
+	// Los parámetros del algoritmo de corto plazo se aplican junto con el reemplazo del planificador
+	cambiaSTS := nueva.SchedulerAlgorithm != anterior.SchedulerAlgorithm ||
+		!reflect.DeepEqual(nueva.MLFQQuantums, anterior.MLFQQuantums)
+
+	// La configuración y el algoritmo se publican juntos, con las colas de READY ya migradas
+	readyMutex.Lock()
+	stsMutex.Lock()
+	configuracionKernel.Store(nueva)
+	if nueva.Seed != anterior.Seed {
+		inicializarSorteo(nueva.Seed)
+	}
+	if cambiaSTS {
+		reemplazarPlanificadorSTS()
+	}
+	stsMutex.Unlock()
+	readyMutex.Unlock()
+	condReady.Broadcast()
+
+	if nueva.ReadyIngressAlgorithm != anterior.ReadyIngressAlgorithm {
+		newMutex.Lock()
+		planificadorLTS = crearPlanificador(TipoLargoPlazo, nueva.ReadyIngressAlgorithm)
+		newMutex.Unlock()
+	}
+
+	if nueva.GradoMultiprogramacion != anterior.GradoMultiprogramacion {
+		redimensionarMultiprogramacion(nueva.GradoMultiprogramacion)
+	}
+
+	// Los recursos nuevos se crean; los existentes conservan sus instancias y asignaciones
+	if !reflect.DeepEqual(nueva.Resources, anterior.Resources) {
+		inicializarRecursos(nueva)
+	}
+	if planificadoresEnMarcha {
+		iniciarDeteccionPeriodica()
+	}
+	condNew.Broadcast()
+
+	utils.InfoLog.Info("Configuración recargada", "ruta", rutaConfiguracion, "cambios", cambios)
+	return cambios, nil
+}
+
+// redimensionarMultiprogramacion cambia el grado de multiprogramación. Si se achica, los procesos
+// ya admitidos conservan su lugar y no se admiten nuevos hasta que se liberen suficientes
+func redimensionarMultiprogramacion(grado int) {
+	if grado <= 0 {
+		grado = 1
+	}
+	gradoMultiprogramacion = grado
+	semaforoMultiprogram.Redimensionar(grado)
+
+	enUso, capacidad := semaforoMultiprogram.Estado()
+	utils.InfoLog.Info("Grado de multiprogramación actualizado", "grado", capacidad, "en_uso", enUso)
+}
+
+// reemplazarPlanificadorSTS crea el nuevo algoritmo de corto plazo y le reencola los procesos
+// listos respetando su orden de llegada a READY. Requiere readyMutex y stsMutex tomados
+func reemplazarPlanificadorSTS() {
+	listos := append([]*PCB{}, colaReady...)
+	listos = append(listos, colaReadyPrioritaria...)
+	for _, cola := range colasMLFQ {
+		listos = append(listos, cola...)
+	}
+	sort.SliceStable(listos, func(i, j int) bool {
+		return listos[i].HoraListo.Before(listos[j].HoraListo)
+	})
+
+	colaReady = []*PCB{}
+	colaReadyPrioritaria = []*PCB{}
+	colasMLFQ = nil
+
+	planificadorSTS = crearPlanificador(TipoCortoPlazo, configKernel().SchedulerAlgorithm)
+	for _, pcb := range listos {
+		planificadorSTS.AlPasarAReady(pcb)
+	}
+
+	if conSegundoPlano, ok := planificadorSTS.(planificadorConSegundoPlano); ok && planificadoresEnMarcha {
+		conSegundoPlano.IniciarSegundoPlano()
+	}
+
+	utils.InfoLog.Info("Planificador de corto plazo reemplazado", "algoritmo", configKernel().SchedulerAlgorithm, "procesos_reencolados", len(listos))
+}
+
+// planificadorVigente indica si el planificador sigue siendo el de corto plazo en uso.
+// Las rutinas de segundo plano terminan cuando su planificador es reemplazado
+func planificadorVigente(planificador Planificador) bool {
+	return planificadorCortoPlazo() == planificador
+}
+
+// camposModificados lista los campos de configuración que cambiaron con su valor nuevo
+func camposModificados(anterior, nueva *KernelConfig) []string {
+	cambios := []string{}
+	valorAnterior := reflect.ValueOf(*anterior)
+	valorNuevo := reflect.ValueOf(*nueva)
+	tipo := valorAnterior.Type()
+
+	for i := 0; i < tipo.NumField(); i++ {
+		if !reflect.DeepEqual(valorAnterior.Field(i).Interface(), valorNuevo.Field(i).Interface()) {
+			clave, _, _ := strings.Cut(tipo.Field(i).Tag.Get("json"), ",")
+			cambios = append(cambios, fmt.Sprintf("%s=%v", clave, valorNuevo.Field(i).Interface()))
+		}
+	}
+	return cambios
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+// Recurso es un semáforo con nombre administrado por el kernel. Con una sola instancia funciona como mutex
+type Recurso struct {
+	Nombre      string
+	Instancias  int         // Instancias declaradas al crearlo
+	Disponibles int         // Instancias libres
+	Asignados   map[int]int // Instancias tomadas por cada PID
+	Bloqueados  []*PCB      // Procesos esperando una instancia, en orden de llegada
+}
+
+var (
+	recursos      = make(map[string]*Recurso)
+	recursosMutex sync.Mutex
+)
+
+// inicializarRecursos crea los recursos declarados en RECURSOS / INSTANCIAS_RECURSOS que todavía no existan
+func inicializarRecursos(config *KernelConfig) {
+	for i, nombre := range config.Resources {
+		instancias := 1
+		if i < len(config.ResourceInstances) {
+			instancias = config.ResourceInstances[i]
+		}
+		if err := crearRecurso(nombre, instancias); err != nil {
+			utils.InfoLog.Warn("Recurso de la configuración no creado", "recurso", nombre, "error", err)
+		}
+	}
+}
+
+// crearRecurso agrega un recurso con la cantidad de instancias indicada
+func crearRecurso(nombre string, instancias int) error {
+	if nombre == "" || esObjetivoDeHijo(nombre) {
+		return fmt.Errorf("nombre de recurso inválido: %q", nombre)
+	}
+	if instancias < 0 {
+		return fmt.Errorf("cantidad de instancias inválida: %d", instancias)
+	}
+
+	recursosMutex.Lock()
+	defer recursosMutex.Unlock()
+
+	if _, existe := recursos[nombre]; existe {
+		return fmt.Errorf("el recurso %s ya existe", nombre)
+	}
+	recursos[nombre] = &Recurso{
+		Nombre:      nombre,
+		Instancias:  instancias,
+		Disponibles: instancias,
+		Asignados:   make(map[int]int),
+	}
+	utils.InfoLog.Info("Recurso creado", "recurso", nombre, "instancias", instancias)
+	return nil
+}
+
+// esObjetivoDeHijo indica si el argumento de WAIT se refiere a un hijo (PID o ANY) y no a un recurso
+func esObjetivoDeHijo(argumento string) bool {
+	if strings.ToUpper(argumento) == "ANY" {
+		return true
+	}
+	for _, c := range argumento {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// esperarRecurso ejecuta WAIT sobre un recurso. Si hay una instancia libre (y con BANQUERO la asignación
+// deja al sistema en estado seguro) se asigna y el proceso sigue ejecutando; si no, se bloquea en la cola
+// del recurso. Un recurso inexistente o un pedido que excede el reclamo declarado finalizan al proceso
+func esperarRecurso(pcb *PCB, nombre string) {
+	recursosMutex.Lock()
+
+	recurso, existe := recursos[nombre]
+	if !existe {
+		recursosMutex.Unlock()
+		utils.ErrorLog.Error("WAIT sobre recurso inexistente", "pid", pcb.PID, "recurso", nombre)
+		FinalizarProceso(pcb, "ERROR_RECURSO_INEXISTENTE")
+		return
+	}
+
+	if excedeReclamo(pcb.PID, recurso) {
+		recursosMutex.Unlock()
+		utils.ErrorLog.Error("WAIT excede el reclamo máximo declarado", "pid", pcb.PID, "recurso", nombre)
+		FinalizarProceso(pcb, "ERROR_RECLAMO_EXCEDIDO")
+		return
+	}
+
+	if recurso.Disponibles > 0 && asignacionSegura(pcb.PID, recurso) {
+		asignarInstancia(pcb.PID, recurso)
+		recursosMutex.Unlock()
+		utils.InfoLog.Info(fmt.Sprintf("(%d) - Toma el recurso %s", pcb.PID, nombre), "disponibles", recurso.Disponibles)
+		return
+	}
+
+	// Se bloquea con recursosMutex tomado para que un SIGNAL posterior lo encuentre ya en BLOCKED
+	recurso.Bloqueados = append(recurso.Bloqueados, pcb)
+	if recurso.Disponibles > 0 {
+		utils.InfoLog.Info(fmt.Sprintf("(%d) - Bloqueado por recurso: %s (asignarlo dejaría al sistema en estado inseguro)", pcb.PID, nombre))
+	} else {
+		utils.InfoLog.Info(fmt.Sprintf("(%d) - Bloqueado por recurso: %s", pcb.PID, nombre))
+	}
+	MoverProcesoABlocked(pcb, "RECURSO_"+nombre)
+	recursosMutex.Unlock()
+
+	if deteccionDeadlock() == DeteccionAlBloquear {
+		go verificarDeadlocks()
+	}
+}
+
+// liberarRecurso ejecuta SIGNAL sobre un recurso: devuelve la instancia y la entrega a los procesos en
+// espera que corresponda. Un recurso inexistente finaliza al proceso
+func liberarRecurso(pcb *PCB, nombre string) {
+	recursosMutex.Lock()
+
+	recurso, existe := recursos[nombre]
+	if !existe {
+		recursosMutex.Unlock()
+		utils.ErrorLog.Error("SIGNAL sobre recurso inexistente", "pid", pcb.PID, "recurso", nombre)
+		FinalizarProceso(pcb, "ERROR_RECURSO_INEXISTENTE")
+		return
+	}
+
+	if recurso.Asignados[pcb.PID] > 0 {
+		recurso.Asignados[pcb.PID]--
+		if recurso.Asignados[pcb.PID] == 0 {
+			delete(recurso.Asignados, pcb.PID)
+		}
+	} else {
+		// Como semáforo se permite señalizar sin haber tomado el recurso
+		utils.InfoLog.Warn("SIGNAL de un proceso que no tenía asignado el recurso", "pid", pcb.PID, "recurso", nombre)
+	}
+	recurso.Disponibles++
+
+	despertados := asignarPendientes()
+	recursosMutex.Unlock()
+
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - Libera el recurso %s", pcb.PID, nombre))
+	despertarAsignados(despertados)
+}
+
+// asignacionPendiente es un proceso bloqueado que recibió la instancia que esperaba
+type asignacionPendiente struct {
+	pcb     *PCB
+	recurso string
+}
+
+// asignarPendientes entrega las instancias libres a los procesos en espera según ORDEN_RECURSOS. Con
+// BANQUERO solo se asigna si el sistema queda en estado seguro. Requiere recursosMutex tomado
+func asignarPendientes() []asignacionPendiente {
+	nombres := make([]string, 0, len(recursos))
+	for nombre := range recursos {
+		nombres = append(nombres, nombre)
+	}
+	sort.Strings(nombres)
+
+	asignados := []asignacionPendiente{}
+	for huboAsignacion := true; huboAsignacion; {
+		huboAsignacion = false
+		for _, nombre := range nombres {
+			recurso := recursos[nombre]
+			if recurso.Disponibles == 0 {
+				continue
+			}
+			for _, pcb := range candidatosEnOrden(recurso) {
+				if !asignacionSegura(pcb.PID, recurso) {
+					continue
+				}
+				removerDeCola(&recurso.Bloqueados, pcb)
+				asignarInstancia(pcb.PID, recurso)
+				utils.InfoLog.Info(fmt.Sprintf("(%d) - Toma el recurso %s al ser desbloqueado", pcb.PID, recurso.Nombre))
+				asignados = append(asignados, asignacionPendiente{pcb: pcb, recurso: nombre})
+				huboAsignacion = true
+				break
+			}
+		}
+	}
+	return asignados
+}
+
+// candidatosEnOrden devuelve los procesos en espera del recurso en el orden de desbloqueo configurado
+func candidatosEnOrden(recurso *Recurso) []*PCB {
+	candidatos := append([]*PCB{}, recurso.Bloqueados...)
+	if ordenRecursos() == "PRIORIDAD" {
+		sort.SliceStable(candidatos, func(i, j int) bool { return candidatos[i].Prioridad < candidatos[j].Prioridad })
+	}
+	return candidatos
+}
+
+func asignarInstancia(pid int, recurso *Recurso) {
+	recurso.Disponibles--
+	recurso.Asignados[pid]++
+}
+
+func despertarAsignados(asignados []asignacionPendiente) {
+	for _, asignado := range asignados {
+		desbloquearProceso(asignado.pcb, "WAIT "+asignado.recurso)
+	}
+}
+
+// ordenRecursos devuelve el orden de desbloqueo configurado: FIFO (por defecto) o PRIORIDAD
+func ordenRecursos() string {
+	if strings.ToUpper(configKernel().ResourceWakeOrder) == "PRIORIDAD" {
+		return "PRIORIDAD"
+	}
+	return "FIFO"
+}
+
+// liberarRecursosDeProceso saca al proceso que finaliza de las colas de espera, descarta sus reclamos y
+// libera, informándolas, las instancias que tenía tomadas
+func liberarRecursosDeProceso(pcb *PCB) {
+	recursosMutex.Lock()
+
+	delete(reclamosMaximos, pcb.PID)
+	for _, recurso := range recursos {
+		removerDeCola(&recurso.Bloqueados, pcb)
+
+		tomadas := recurso.Asignados[pcb.PID]
+		if tomadas == 0 {
+			continue
+		}
+		delete(recurso.Asignados, pcb.PID)
+		recurso.Disponibles += tomadas
+		utils.InfoLog.Warn(fmt.Sprintf("(%d) - Finaliza con el recurso %s tomado, se liberan %d instancias", pcb.PID, recurso.Nombre, tomadas))
+	}
+
+	despertados := asignarPendientes()
+	recursosMutex.Unlock()
+
+	despertarAsignados(despertados)
+}
+
+// estadoRecurso es la vista de un recurso para la consola y la API de administración
+type estadoRecurso struct {
+	Nombre      string      `json:"nombre"`
+	Instancias  int         `json:"instancias"`
+	Disponibles int         `json:"disponibles"`
+	Asignados   map[int]int `json:"asignados"`
+	Bloqueados  []int       `json:"bloqueados"`
+}
+
+// estadoRecursos devuelve una copia del estado de todos los recursos, ordenados por nombre
+func estadoRecursos() []estadoRecurso {
+	recursosMutex.Lock()
+	defer recursosMutex.Unlock()
+
+	estados := make([]estadoRecurso, 0, len(recursos))
+	for _, recurso := range recursos {
+		asignados := make(map[int]int, len(recurso.Asignados))
+		for pid, cantidad := range recurso.Asignados {
+			asignados[pid] = cantidad
+		}
+		estados = append(estados, estadoRecurso{
+			Nombre:      recurso.Nombre,
+			Instancias:  recurso.Instancias,
+			Disponibles: recurso.Disponibles,
+			Asignados:   asignados,
+			Bloqueados:  pidsDeCola(recurso.Bloqueados),
+		})
+	}
+	sort.Slice(estados, func(i, j int) bool { return estados[i].Nombre < estados[j].Nombre })
+	return estados
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+const archivoReportePorDefecto = "kernel-reporte.json"
+
+var (
+	// inicioPlanificacion es el instante en que se iniciaron los planificadores; base de throughput y utilización
+	inicioPlanificacion time.Time
+
+	// Contabilidad de ocupación de recursos para el reporte (ms)
+	ocupacionCPU      = make(map[string]float64)
+	ocupacionIO       = make(map[string]float64)
+	ioOcupadoDesde    = make(map[string]time.Time)
+	suspensionesTotal int
+	estadisticasMutex sync.Mutex
+)
+
+// estadisticaTiempos resume una serie de tiempos (ms)
+type estadisticaTiempos struct {
+	Promedio float64 `json:"promedio_ms"`
+	P50      float64 `json:"p50_ms"`
+	P90      float64 `json:"p90_ms"`
+	P99      float64 `json:"p99_ms"`
+	Maximo   float64 `json:"maximo_ms"`
+}
+
+// ReporteSistema es el resumen agregado de una corrida del kernel
+type ReporteSistema struct {
+	Generado                string             `json:"generado"`
+	AlgoritmoCortoPlazo     string             `json:"algoritmo_corto_plazo"`
+	AlgoritmoIngresoReady   string             `json:"algoritmo_ingreso_ready"`
+	DuracionMs              float64            `json:"duracion_ms"`
+	ProcesosFinalizados     int                `json:"procesos_finalizados"`
+	ProcesosVivos           int                `json:"procesos_vivos"`
+	ThroughputPorSegundo    float64            `json:"throughput_por_segundo"`
+	Retorno                 estadisticaTiempos `json:"retorno"`
+	Espera                  estadisticaTiempos `json:"espera"`
+	Respuesta               estadisticaTiempos `json:"respuesta"`
+	UtilizacionCPU          map[string]float64 `json:"utilizacion_cpu"`
+	UtilizacionIO           map[string]float64 `json:"utilizacion_io"`
+	Suspensiones            int                `json:"suspensiones"`
+	FinalizacionesPorMotivo map[string]int     `json:"finalizaciones_por_motivo,omitempty"`
+}
+
+// marcarInicioPlanificacion registra el comienzo de la medición
+func marcarInicioPlanificacion() {
+	estadisticasMutex.Lock()
+	defer estadisticasMutex.Unlock()
+	if inicioPlanificacion.IsZero() {
+		inicioPlanificacion = utils.Ahora()
+	}
+}
+
+// registrarOcupacionCPU suma una ráfaga al tiempo ocupado de la CPU que la ejecutó
+func registrarOcupacionCPU(cpu string, rafaga float64) {
+	if cpu == "" {
+		return
+	}
+	estadisticasMutex.Lock()
+	defer estadisticasMutex.Unlock()
+	ocupacionCPU[cpu] += rafaga
+}
+
+// registrarInicioOcupacionIO marca el dispositivo como ocupado si estaba libre
+func registrarInicioOcupacionIO(dispositivo string) {
+	estadisticasMutex.Lock()
+	defer estadisticasMutex.Unlock()
+	if _, ocupado := ioOcupadoDesde[dispositivo]; !ocupado {
+		ioOcupadoDesde[dispositivo] = utils.Ahora()
+	}
+}
+
+// registrarFinOcupacionIO acumula el intervalo ocupado del dispositivo cuando deja de atender peticiones
+func registrarFinOcupacionIO(dispositivo string) {
+	estadisticasMutex.Lock()
+	defer estadisticasMutex.Unlock()
+	if desde, ocupado := ioOcupadoDesde[dispositivo]; ocupado {
+		ocupacionIO[dispositivo] += utils.Desde(desde).Seconds() * 1000
+		delete(ioOcupadoDesde, dispositivo)
+	}
+}
+
+// registrarSuspension cuenta una suspensión de proceso
+func registrarSuspension() {
+	estadisticasMutex.Lock()
+	defer estadisticasMutex.Unlock()
+	suspensionesTotal++
+}
+
+// generarReporte arma el reporte con los procesos finalizados y la ocupación de CPUs y dispositivos
+func generarReporte() ReporteSistema {
+	ahora := utils.Ahora()
+
+	exitMutex.Lock()
+	finalizados := append([]*PCB{}, colaExit...)
+	exitMutex.Unlock()
+
+	mapaMutex.RLock()
+	vivos := len(mapaPCBs)
+	mapaMutex.RUnlock()
+
+	retornos := []float64{}
+	esperas := []float64{}
+	respuestas := []float64{}
+	motivos := map[string]int{}
+	for _, pcb := range finalizados {
+		retornos = append(retornos, pcb.TiempoRetorno())
+		esperas = append(esperas, pcb.TiempoEspera())
+		if respuesta := pcb.TiempoRespuesta(); respuesta >= 0 {
+			respuestas = append(respuestas, respuesta)
+		}
+		if pcb.MotivoFinalizacion != "" {
+			motivos[pcb.MotivoFinalizacion]++
+		}
+	}
+
+	estadisticasMutex.Lock()
+	inicio := inicioPlanificacion
+	if inicio.IsZero() {
+		inicio = ahora
+	}
+	duracion := ahora.Sub(inicio).Seconds() * 1000
+
+	utilizacionCPU := map[string]float64{}
+	cpuClientsMutex.Lock()
+	for nombre := range cpuClients {
+		utilizacionCPU[nombre] = porcentaje(ocupacionCPU[nombre], duracion)
+	}
+	cpuClientsMutex.Unlock()
+	// Las CPUs y dispositivos dados de baja también cuentan por el tiempo que estuvieron ocupados
+	for nombre, ocupado := range ocupacionCPU {
+		if _, registrada := utilizacionCPU[nombre]; !registrada {
+			utilizacionCPU[nombre] = porcentaje(ocupado, duracion)
+		}
+	}
+
+	utilizacionIO := map[string]float64{}
+	dispositivosIOMutex.RLock()
+	for nombre := range dispositivosIO {
+		ocupado := ocupacionIO[nombre]
+		if desde, enCurso := ioOcupadoDesde[nombre]; enCurso {
+			ocupado += ahora.Sub(desde).Seconds() * 1000
+		}
+		utilizacionIO[nombre] = porcentaje(ocupado, duracion)
+	}
+	dispositivosIOMutex.RUnlock()
+	for nombre, ocupado := range ocupacionIO {
+		if _, registrado := utilizacionIO[nombre]; !registrado {
+			utilizacionIO[nombre] = porcentaje(ocupado, duracion)
+		}
+	}
+	suspensiones := suspensionesTotal
+	estadisticasMutex.Unlock()
+
+	throughput := 0.0
+	if duracion > 0 {
+		throughput = float64(len(finalizados)) / (duracion / 1000)
+	}
+
+	return ReporteSistema{
+		Generado:                ahora.Format(time.RFC3339),
+		AlgoritmoCortoPlazo:     configKernel().SchedulerAlgorithm,
+		AlgoritmoIngresoReady:   configKernel().ReadyIngressAlgorithm,
+		DuracionMs:              duracion,
+		ProcesosFinalizados:     len(finalizados),
+		ProcesosVivos:           vivos,
+		ThroughputPorSegundo:    throughput,
+		Retorno:                 calcularEstadistica(retornos),
+		Espera:                  calcularEstadistica(esperas),
+		Respuesta:               calcularEstadistica(respuestas),
+		UtilizacionCPU:          utilizacionCPU,
+		UtilizacionIO:           utilizacionIO,
+		Suspensiones:            suspensiones,
+		FinalizacionesPorMotivo: motivos,
+	}
+}
+
+// calcularEstadistica devuelve promedio, percentiles (por rango más cercano) y máximo de la serie
+func calcularEstadistica(valores []float64) estadisticaTiempos {
+	if len(valores) == 0 {
+		return estadisticaTiempos{}
+	}
+
+	ordenados := append([]float64{}, valores...)
+	sort.Float64s(ordenados)
+
+	suma := 0.0
+	for _, v := range ordenados {
+		suma += v
+	}
+
+	percentil := func(p float64) float64 {
+		indice := int(math.Ceil(p/100*float64(len(ordenados)))) - 1
+		if indice < 0 {
+			indice = 0
+		}
+		return ordenados[indice]
+	}
+
+	return estadisticaTiempos{
+		Promedio: suma / float64(len(ordenados)),
+		P50:      percentil(50),
+		P90:      percentil(90),
+		P99:      percentil(99),
+		Maximo:   ordenados[len(ordenados)-1],
+	}
+}
+
+func porcentaje(parte, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Min(parte/total*100, 100)
+}
+
+// archivoReporte devuelve la ruta del reporte JSON configurada
+func archivoReporte() string {
+	if configKernel().ReportFile != "" {
+		return configKernel().ReportFile
+	}
+	return archivoReportePorDefecto
+}
+
+// guardarReporte escribe el reporte en formato JSON
+func guardarReporte(reporte ReporteSistema) error {
+	datos, err := json.MarshalIndent(reporte, "", "    ")
+	if err != nil {
+		return fmt.Errorf("error serializando reporte: %w", err)
+	}
+
+	if err := os.WriteFile(archivoReporte(), datos, 0644); err != nil {
+		return fmt.Errorf("error escribiendo reporte %s: %w", archivoReporte(), err)
+	}
+	return nil
+}
+
+// emitirReporte genera el reporte, lo guarda en JSON y devuelve su versión en texto
+func emitirReporte() string {
+	reporte := generarReporte()
+	if err := guardarReporte(reporte); err != nil {
+		utils.ErrorLog.Error("No se pudo guardar el reporte del sistema", "error", err)
+	} else {
+		utils.InfoLog.Info("Reporte del sistema guardado", "archivo", archivoReporte())
+	}
+	return formatearReporte(reporte)
+}
+
+// formatearReporte devuelve el reporte en texto para la consola
+func formatearReporte(reporte ReporteSistema) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "=== Reporte del sistema (%s / %s) ===\n", reporte.AlgoritmoCortoPlazo, reporte.AlgoritmoIngresoReady)
+	fmt.Fprintf(&b, "Duración: %.0f ms - Finalizados: %d - Vivos: %d - Throughput: %.3f procesos/s\n",
+		reporte.DuracionMs, reporte.ProcesosFinalizados, reporte.ProcesosVivos, reporte.ThroughputPorSegundo)
+
+	series := []struct {
+		nombre string
+		est    estadisticaTiempos
+	}{
+		{"Retorno", reporte.Retorno},
+		{"Espera", reporte.Espera},
+		{"Respuesta", reporte.Respuesta},
+	}
+	for _, serie := range series {
+		fmt.Fprintf(&b, "%-10s promedio %.0f ms - p50 %.0f - p90 %.0f - p99 %.0f - máx %.0f\n",
+			serie.nombre, serie.est.Promedio, serie.est.P50, serie.est.P90, serie.est.P99, serie.est.Maximo)
+	}
+
+	fmt.Fprintf(&b, "Utilización de CPU:%s\n", formatearUtilizacion(reporte.UtilizacionCPU))
+	fmt.Fprintf(&b, "Utilización de IO:%s\n", formatearUtilizacion(reporte.UtilizacionIO))
+	fmt.Fprintf(&b, "Suspensiones: %d", reporte.Suspensiones)
+	return b.String()
+}
+
+func formatearUtilizacion(utilizacion map[string]float64) string {
+	if len(utilizacion) == 0 {
+		return " (sin registrar)"
+	}
+
+	nombres := make([]string, 0, len(utilizacion))
+	for nombre := range utilizacion {
+		nombres = append(nombres, nombre)
+	}
+	sort.Strings(nombres)
+
+	var b strings.Builder
+	for _, nombre := range nombres {
+		fmt.Fprintf(&b, " %s %.1f%%", nombre, utilizacion[nombre])
+	}
+	return b.String()
+}
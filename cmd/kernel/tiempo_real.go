@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+var (
+	deadlinesPerdidosTotales int
+	tiempoRealMutex          sync.Mutex
+)
+
+func init() {
+	RegistrarPlanificador(TipoCortoPlazo, "EDF", func() Planificador { return &planificadorTiempoReal{nombre: "EDF"} })
+	RegistrarPlanificador(TipoCortoPlazo, "RM", func() Planificador { return &planificadorTiempoReal{nombre: "RM"} })
+}
+
+// asignarOpcionesTiempoReal convierte al proceso en tiempo real si se declararon PERIODO y WCET.
+// DEADLINE es opcional y por defecto coincide con el período
+func asignarOpcionesTiempoReal(pcb *PCB, opciones map[string]interface{}) {
+	periodo, _ := opciones["PERIODO"].(float64)
+	wcet, _ := opciones["WCET"].(float64)
+	deadline, _ := opciones["DEADLINE"].(float64)
+
+	if periodo <= 0 && wcet <= 0 {
+		return
+	}
+	if periodo <= 0 || wcet <= 0 {
+		utils.InfoLog.Warn("Parámetros de tiempo real incompletos, se crea como proceso común", "pid", pcb.PID, "opciones", opciones)
+		return
+	}
+	if deadline <= 0 {
+		deadline = periodo
+	}
+
+	pcb.TiempoReal = true
+	pcb.Periodo = periodo
+	pcb.Deadline = deadline
+	pcb.WCET = wcet
+
+	utils.InfoLog.Info("Proceso de tiempo real declarado", "pid", pcb.PID, "periodo_ms", periodo, "deadline_ms", deadline, "wcet_ms", wcet)
+}
+
+// utilizacion devuelve la fracción de CPU que reserva el proceso (WCET sobre el menor entre deadline y período)
+func (pcb *PCB) utilizacion() float64 {
+	return pcb.WCET / math.Min(pcb.Deadline, pcb.Periodo)
+}
+
+// planificadorTiempoReal despacha primero los procesos de tiempo real (EDF: deadline absoluto más cercano,
+// RM: menor período) y deja a los procesos comunes en segundo plano, en orden FIFO
+type planificadorTiempoReal struct {
+	planificadorBase
+	nombre string
+}
+
+// tieneMasUrgencia indica si a debe ejecutar antes que b. Ambos son de tiempo real
+func (p *planificadorTiempoReal) tieneMasUrgencia(a, b *PCB) bool {
+	if p.nombre == "EDF" {
+		if !a.DeadlineAbsoluto.Equal(b.DeadlineAbsoluto) {
+			return a.DeadlineAbsoluto.Before(b.DeadlineAbsoluto)
+		}
+	} else if a.Periodo != b.Periodo {
+		return a.Periodo < b.Periodo
+	}
+	return a.HoraListo.Before(b.HoraListo)
+}
+
+func (p *planificadorTiempoReal) SeleccionarSiguiente() *PCB {
+	if len(colaReady) == 0 {
+		return nil
+	}
+
+	var seleccionado *PCB
+	for _, pcb := range colaReady {
+		if pcb.TiempoReal && (seleccionado == nil || p.tieneMasUrgencia(pcb, seleccionado)) {
+			seleccionado = pcb
+		}
+	}
+
+	if seleccionado == nil {
+		return colaReady[0]
+	}
+
+	utils.InfoLog.Info(p.nombre+" seleccionó proceso de tiempo real", "pid", seleccionado.PID, "periodo_ms", seleccionado.Periodo, "deadline", seleccionado.DeadlineAbsoluto.Format("15:04:05.000"))
+	return seleccionado
+}
+
+// DebeDesalojar desaloja primero a un proceso común y, si no hay, al de tiempo real menos urgente
+func (p *planificadorTiempoReal) DebeDesalojar(candidato *PCB, enEjecucion []*PCB) *PCB {
+	if !candidato.TiempoReal {
+		return nil
+	}
+
+	var procesoADesalojar *PCB
+	for _, pcbEnExec := range enEjecucion {
+		if !pcbEnExec.TiempoReal {
+			procesoADesalojar = pcbEnExec
+			break
+		}
+		if p.tieneMasUrgencia(candidato, pcbEnExec) &&
+			(procesoADesalojar == nil || p.tieneMasUrgencia(procesoADesalojar, pcbEnExec)) {
+			procesoADesalojar = pcbEnExec
+		}
+	}
+
+	if procesoADesalojar != nil {
+		utils.InfoLog.Info(fmt.Sprintf("(%d) - Desalojado por algoritmo %s", procesoADesalojar.PID, p.nombre))
+	}
+	return procesoADesalojar
+}
+
+// AlPasarAReady libera una nueva activación si el proceso de tiempo real no tiene una en curso
+func (p *planificadorTiempoReal) AlPasarAReady(pcb *PCB) {
+	if pcb.TiempoReal && !pcb.ActivacionEnCurso {
+		liberarActivacion(pcb)
+	}
+	colaReady = append(colaReady, pcb)
+}
+
+// AlBloquearse da por terminada la activación en curso
+func (p *planificadorTiempoReal) AlBloquearse(pcb *PCB, motivo string) {
+	finalizarActivacion(pcb)
+}
+
+func (p *planificadorTiempoReal) AlFinalizarRafaga(pcb *PCB, nuevoEstado string) {
+	if nuevoEstado == EstadoExit {
+		finalizarActivacion(pcb)
+	}
+}
+
+// Admitir aplica el test de planificabilidad sobre los procesos de tiempo real ya admitidos más el candidato.
+// EDF: U <= 1. RM: cota de Liu-Layland U <= n(2^(1/n) - 1). Un proceso que ya salió de NEW no se vuelve a evaluar
+func (p *planificadorTiempoReal) Admitir(candidato *PCB) bool {
+	if !candidato.TiempoReal || candidato.Estado != EstadoNew {
+		return true
+	}
+
+	utilizacionTotal := candidato.utilizacion()
+	cantidad := 1
+
+	mapaMutex.RLock()
+	for _, pcb := range mapaPCBs {
+		if pcb.TiempoReal && pcb != candidato && pcb.Estado != EstadoNew && pcb.Estado != EstadoExit {
+			utilizacionTotal += pcb.utilizacion()
+			cantidad++
+		}
+	}
+	mapaMutex.RUnlock()
+
+	cota := 1.0
+	if p.nombre == "RM" {
+		n := float64(cantidad)
+		cota = n * (math.Pow(2, 1/n) - 1)
+	}
+
+	admitido := utilizacionTotal <= cota
+	utils.InfoLog.Info("Test de planificabilidad "+p.nombre,
+		"pid", candidato.PID,
+		"procesos_tiempo_real", cantidad,
+		"utilizacion", fmt.Sprintf("%.3f", utilizacionTotal),
+		"cota", fmt.Sprintf("%.3f", cota),
+		"admitido", admitido)
+	return admitido
+}
+
+// liberarActivacion inicia una activación esporádica: no antes de un período desde la anterior
+func liberarActivacion(pcb *PCB) {
+	inicio := utils.Ahora()
+	if pcb.NumeroActivacion > 0 {
+		if siguiente := pcb.InicioActivacion.Add(time.Duration(pcb.Periodo) * time.Millisecond); siguiente.After(inicio) {
+			inicio = siguiente
+		}
+	}
+
+	pcb.NumeroActivacion++
+	pcb.ActivacionEnCurso = true
+	pcb.InicioActivacion = inicio
+	pcb.DeadlineAbsoluto = inicio.Add(time.Duration(pcb.Deadline) * time.Millisecond)
+
+	activacion := pcb.NumeroActivacion
+	if pcb.timerDeadline != nil {
+		pcb.timerDeadline.Detener()
+	}
+	pcb.timerDeadline = utils.Programar(pcb.DeadlineAbsoluto.Sub(utils.Ahora()), func() {
+		vencimientoDeadline(pcb, activacion)
+	})
+
+	utils.InfoLog.Info("Activación de tiempo real liberada", "pid", pcb.PID, "activacion", activacion, "deadline", pcb.DeadlineAbsoluto.Format("15:04:05.000"))
+}
+
+// finalizarActivacion cierra la activación en curso y cancela su control de deadline
+func finalizarActivacion(pcb *PCB) {
+	if !pcb.TiempoReal || !pcb.ActivacionEnCurso {
+		return
+	}
+	pcb.ActivacionEnCurso = false
+	if pcb.timerDeadline != nil {
+		pcb.timerDeadline.Detener()
+		pcb.timerDeadline = nil
+	}
+}
+
+// vencimientoDeadline registra un deadline perdido si la activación sigue sin terminar
+func vencimientoDeadline(pcb *PCB, activacion int) {
+	if !pcb.ActivacionEnCurso || pcb.NumeroActivacion != activacion || pcb.Estado == EstadoExit {
+		return
+	}
+
+	pcb.DeadlinesPerdidos++
+	tiempoRealMutex.Lock()
+	deadlinesPerdidosTotales++
+	totales := deadlinesPerdidosTotales
+	tiempoRealMutex.Unlock()
+
+	utils.InfoLog.Warn(fmt.Sprintf("(%d) - Deadline perdido en la activación %d", pcb.PID, activacion))
+	utils.InfoLog.Warn("Deadline perdido", "pid", pcb.PID, "activacion", activacion, "estado", pcb.Estado, "perdidos_proceso", pcb.DeadlinesPerdidos, "perdidos_totales", totales)
+}
+
+// reportarTiempoReal detiene el control de deadlines e informa los resultados del proceso
+func reportarTiempoReal(pcb *PCB) {
+	finalizarActivacion(pcb)
+	utils.InfoLog.Info(fmt.Sprintf("(%d) - Tiempo real: %d activaciones, %d deadlines perdidos", pcb.PID, pcb.NumeroActivacion, pcb.DeadlinesPerdidos))
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+const (
+	archivoTrazaPorDefecto = "kernel-traza.jsonl"
+	anchoGanttPorDefecto   = 100
+)
+
+// Tipos de evento de la traza de planificación
+const (
+	EventoEstado      = "ESTADO"
+	EventoDespacho    = "DESPACHO"
+	EventoDesalojo    = "DESALOJO"
+	EventoIOInicio    = "IO_INICIO"
+	EventoIOFin       = "IO_FIN"
+	EventoSwapSalida  = "SWAP_SALIDA"
+	EventoSwapEntrada = "SWAP_ENTRADA"
+)
+
+// EventoTraza es una línea del archivo de traza
+type EventoTraza struct {
+	Marca   time.Time `json:"marca"`
+	Tipo    string    `json:"tipo"`
+	PID     int       `json:"pid"`
+	Desde   string    `json:"desde,omitempty"`
+	Hacia   string    `json:"hacia,omitempty"`
+	Recurso string    `json:"recurso,omitempty"` // CPU o dispositivo de IO involucrado
+	Detalle string    `json:"detalle,omitempty"`
+}
+
+// intervaloTraza es un tramo continuo de un proceso en un estado (o de un dispositivo atendiendo a un proceso)
+type intervaloTraza struct {
+	PID     int
+	Estado  string
+	Recurso string
+	Inicio  time.Time
+	Fin     time.Time
+}
+
+var (
+	eventosTraza []EventoTraza
+	archivoTraza *os.File
+	trazaMutex   sync.Mutex
+)
+
+// rutaTraza devuelve la ruta del archivo de traza configurada
+func rutaTraza() string {
+	if configKernel() != nil && configKernel().TraceFile != "" {
+		return configKernel().TraceFile
+	}
+	return archivoTrazaPorDefecto
+}
+
+// registrarEventoTraza guarda el evento en memoria y lo agrega como línea JSON al archivo de traza
+func registrarEventoTraza(evento EventoTraza) {
+	if evento.Marca.IsZero() {
+		evento.Marca = utils.Ahora()
+	}
+
+	trazaMutex.Lock()
+	defer trazaMutex.Unlock()
+
+	eventosTraza = append(eventosTraza, evento)
+
+	if archivoTraza == nil {
+		archivo, err := os.Create(rutaTraza())
+		if err != nil {
+			utils.ErrorLog.Error("No se pudo crear el archivo de traza", "archivo", rutaTraza(), "error", err)
+			return
+		}
+		archivoTraza = archivo
+		utils.InfoLog.Info("Traza de planificación habilitada", "archivo", rutaTraza())
+	}
+
+	if err := json.NewEncoder(archivoTraza).Encode(evento); err != nil {
+		utils.ErrorLog.Error("Error escribiendo evento de traza", "error", err)
+	}
+}
+
+// trazarEvento registra un evento puntual de un proceso
+func trazarEvento(tipo string, pid int, recurso string, detalle string) {
+	registrarEventoTraza(EventoTraza{Tipo: tipo, PID: pid, Recurso: recurso, Detalle: detalle})
+}
+
+// eventosRegistrados devuelve una copia de los eventos de la corrida actual
+func eventosRegistrados() []EventoTraza {
+	trazaMutex.Lock()
+	defer trazaMutex.Unlock()
+	return append([]EventoTraza{}, eventosTraza...)
+}
+
+// leerTraza carga los eventos de un archivo de traza JSONL
+func leerTraza(ruta string) ([]EventoTraza, error) {
+	archivo, err := os.Open(ruta)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo traza %s: %w", ruta, err)
+	}
+	defer archivo.Close()
+
+	eventos := []EventoTraza{}
+	lector := bufio.NewScanner(archivo)
+	for linea := 1; lector.Scan(); linea++ {
+		var evento EventoTraza
+		if err := json.Unmarshal(lector.Bytes(), &evento); err != nil {
+			return nil, fmt.Errorf("línea %d de %s inválida: %w", linea, ruta, err)
+		}
+		eventos = append(eventos, evento)
+	}
+	return eventos, lector.Err()
+}
+
+// ordenarEventos ordena los eventos por marca de tiempo conservando el orden de registro en los empates
+func ordenarEventos(eventos []EventoTraza) []EventoTraza {
+	ordenados := append([]EventoTraza{}, eventos...)
+	sort.SliceStable(ordenados, func(i, j int) bool { return ordenados[i].Marca.Before(ordenados[j].Marca) })
+	return ordenados
+}
+
+// intervalosDeEstado reconstruye los tramos de cada proceso en cada estado a partir de los cambios de estado.
+// Los tramos abiertos se cierran en el instante fin
+func intervalosDeEstado(eventos []EventoTraza, fin time.Time) []intervaloTraza {
+	intervalos := []intervaloTraza{}
+	abiertos := map[int]*intervaloTraza{}
+
+	for _, evento := range eventos {
+		if evento.Tipo != EventoEstado {
+			continue
+		}
+		if abierto, ok := abiertos[evento.PID]; ok {
+			abierto.Fin = evento.Marca
+			intervalos = append(intervalos, *abierto)
+			delete(abiertos, evento.PID)
+		}
+		if evento.Hacia != EstadoExit {
+			recurso := ""
+			if evento.Hacia == EstadoExec {
+				recurso = evento.Recurso
+			}
+			abiertos[evento.PID] = &intervaloTraza{PID: evento.PID, Estado: evento.Hacia, Recurso: recurso, Inicio: evento.Marca}
+		}
+	}
+
+	for _, abierto := range abiertos {
+		abierto.Fin = fin
+		intervalos = append(intervalos, *abierto)
+	}
+	return intervalos
+}
+
+// intervalosDeIO reconstruye los tramos en que cada dispositivo atendió a cada proceso
+func intervalosDeIO(eventos []EventoTraza, fin time.Time) []intervaloTraza {
+	intervalos := []intervaloTraza{}
+	abiertos := map[int]*intervaloTraza{}
+
+	for _, evento := range eventos {
+		switch evento.Tipo {
+		case EventoIOInicio:
+			abiertos[evento.PID] = &intervaloTraza{PID: evento.PID, Estado: "IO", Recurso: evento.Recurso, Inicio: evento.Marca}
+		case EventoIOFin:
+			if abierto, ok := abiertos[evento.PID]; ok {
+				abierto.Fin = evento.Marca
+				intervalos = append(intervalos, *abierto)
+				delete(abiertos, evento.PID)
+			}
+		}
+	}
+
+	for _, abierto := range abiertos {
+		abierto.Fin = fin
+		intervalos = append(intervalos, *abierto)
+	}
+	return intervalos
+}
+
+// simboloEstado es el carácter con que se dibuja cada estado en el diagrama ASCII
+func simboloEstado(estado string) byte {
+	switch estado {
+	case EstadoNew:
+		return 'n'
+	case EstadoReady:
+		return '.'
+	case EstadoExec:
+		return '#'
+	case EstadoBlocked:
+		return 'b'
+	case EstadoSuspReady:
+		return 's'
+	case EstadoSuspBlocked:
+		return 'S'
+	}
+	return '?'
+}
+
+// simboloPID es el carácter con que se dibuja un PID en las filas de CPU y dispositivos
+func simboloPID(pid int) byte {
+	const simbolos = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	return simbolos[pid%len(simbolos)]
+}
+
+// renderizarGanttASCII dibuja una fila por proceso (estado en cada instante), una por CPU
+// y una por dispositivo (PID atendido en cada instante)
+func renderizarGanttASCII(eventos []EventoTraza, ancho int) string {
+	if len(eventos) == 0 {
+		return "Traza vacía"
+	}
+	eventos = ordenarEventos(eventos)
+	if ancho <= 0 {
+		ancho = anchoGanttPorDefecto
+	}
+
+	inicio := eventos[0].Marca
+	fin := eventos[len(eventos)-1].Marca
+	if !fin.After(inicio) {
+		fin = inicio.Add(time.Millisecond)
+	}
+	msPorColumna := float64(fin.Sub(inicio).Milliseconds()) / float64(ancho)
+	if msPorColumna <= 0 {
+		msPorColumna = 1
+	}
+
+	columna := func(t time.Time) int {
+		c := int(float64(t.Sub(inicio).Milliseconds()) / msPorColumna)
+		if c >= ancho {
+			c = ancho - 1
+		}
+		return c
+	}
+	pintar := func(fila []byte, intervalo intervaloTraza, simbolo byte) {
+		// Cada tramo ocupa al menos una columna para que las ráfagas cortas sean visibles
+		desde, hasta := columna(intervalo.Inicio), columna(intervalo.Fin)
+		if hasta <= desde {
+			hasta = desde + 1
+		}
+		for c := desde; c < hasta && c < ancho; c++ {
+			fila[c] = simbolo
+		}
+	}
+	filaVacia := func() []byte {
+		return []byte(strings.Repeat(" ", ancho))
+	}
+
+	filasPID := map[int][]byte{}
+	filasCPU := map[string][]byte{}
+	for _, intervalo := range intervalosDeEstado(eventos, fin) {
+		if _, ok := filasPID[intervalo.PID]; !ok {
+			filasPID[intervalo.PID] = filaVacia()
+		}
+		pintar(filasPID[intervalo.PID], intervalo, simboloEstado(intervalo.Estado))
+
+		if intervalo.Estado == EstadoExec && intervalo.Recurso != "" {
+			if _, ok := filasCPU[intervalo.Recurso]; !ok {
+				filasCPU[intervalo.Recurso] = filaVacia()
+			}
+			pintar(filasCPU[intervalo.Recurso], intervalo, simboloPID(intervalo.PID))
+		}
+	}
+
+	filasIO := map[string][]byte{}
+	for _, intervalo := range intervalosDeIO(eventos, fin) {
+		if _, ok := filasIO[intervalo.Recurso]; !ok {
+			filasIO[intervalo.Recurso] = filaVacia()
+		}
+		pintar(filasIO[intervalo.Recurso], intervalo, simboloPID(intervalo.PID))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Gantt: %d ms, %.0f ms por columna (# EXEC, . READY, b BLOCKED, S SUSP.BLOCKED, s SUSP.READY, n NEW)\n",
+		fin.Sub(inicio).Milliseconds(), msPorColumna)
+
+	pids := make([]int, 0, len(filasPID))
+	for pid := range filasPID {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	for _, pid := range pids {
+		fmt.Fprintf(&b, "%-10s|%s|\n", fmt.Sprintf("PID %d", pid), filasPID[pid])
+	}
+
+	for _, filas := range []map[string][]byte{filasCPU, filasIO} {
+		nombres := make([]string, 0, len(filas))
+		for nombre := range filas {
+			nombres = append(nombres, nombre)
+		}
+		sort.Strings(nombres)
+		for _, nombre := range nombres {
+			fmt.Fprintf(&b, "%-10.10s|%s|\n", nombre, filas[nombre])
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// eventoChrome es un evento del formato Trace Event de Chrome (chrome://tracing, Perfetto)
+type eventoChrome struct {
+	Nombre    string                 `json:"name"`
+	Categoria string                 `json:"cat,omitempty"`
+	Fase      string                 `json:"ph"`
+	Marca     int64                  `json:"ts"`
+	Duracion  int64                  `json:"dur,omitempty"`
+	Proceso   int                    `json:"pid"`
+	Hilo      int                    `json:"tid"`
+	Alcance   string                 `json:"s,omitempty"`
+	Args      map[string]interface{} `json:"args,omitempty"`
+}
+
+// Grupos del diagrama de Chrome: una fila por proceso, por CPU y por dispositivo
+const (
+	grupoChromeProcesos = 1
+	grupoChromeCPUs     = 2
+	grupoChromeIO       = 3
+)
+
+// exportarTrazaChrome convierte la traza al formato JSON de Chrome trace-event
+func exportarTrazaChrome(eventos []EventoTraza) ([]byte, error) {
+	salida := []eventoChrome{}
+	if len(eventos) == 0 {
+		return json.MarshalIndent(map[string]interface{}{"traceEvents": salida}, "", "  ")
+	}
+	eventos = ordenarEventos(eventos)
+
+	inicio := eventos[0].Marca
+	fin := eventos[len(eventos)-1].Marca
+	microsegundos := func(t time.Time) int64 { return t.Sub(inicio).Microseconds() }
+
+	metadatos := func(grupo int, nombre string) {
+		salida = append(salida, eventoChrome{Nombre: "process_name", Fase: "M", Proceso: grupo, Args: map[string]interface{}{"name": nombre}})
+	}
+	metadatos(grupoChromeProcesos, "Procesos")
+	metadatos(grupoChromeCPUs, "CPUs")
+	metadatos(grupoChromeIO, "Dispositivos IO")
+
+	hilos := map[string]int{}
+	hiloDe := func(grupo int, nombre string) int {
+		clave := fmt.Sprintf("%d/%s", grupo, nombre)
+		if hilo, ok := hilos[clave]; ok {
+			return hilo
+		}
+		hilo := len(hilos) + 1
+		hilos[clave] = hilo
+		salida = append(salida, eventoChrome{Nombre: "thread_name", Fase: "M", Proceso: grupo, Hilo: hilo, Args: map[string]interface{}{"name": nombre}})
+		return hilo
+	}
+	tramo := func(grupo int, fila string, nombre string, intervalo intervaloTraza) {
+		salida = append(salida, eventoChrome{
+			Nombre:    nombre,
+			Categoria: intervalo.Estado,
+			Fase:      "X",
+			Marca:     microsegundos(intervalo.Inicio),
+			Duracion:  intervalo.Fin.Sub(intervalo.Inicio).Microseconds(),
+			Proceso:   grupo,
+			Hilo:      hiloDe(grupo, fila),
+			Args:      map[string]interface{}{"pid": intervalo.PID, "recurso": intervalo.Recurso},
+		})
+	}
+
+	for _, intervalo := range intervalosDeEstado(eventos, fin) {
+		tramo(grupoChromeProcesos, fmt.Sprintf("PID %d", intervalo.PID), intervalo.Estado, intervalo)
+		if intervalo.Estado == EstadoExec && intervalo.Recurso != "" {
+			tramo(grupoChromeCPUs, intervalo.Recurso, fmt.Sprintf("PID %d", intervalo.PID), intervalo)
+		}
+	}
+	for _, intervalo := range intervalosDeIO(eventos, fin) {
+		tramo(grupoChromeIO, intervalo.Recurso, fmt.Sprintf("PID %d", intervalo.PID), intervalo)
+	}
+
+	// Desalojos y swaps como eventos instantáneos sobre la fila del proceso
+	for _, evento := range eventos {
+		switch evento.Tipo {
+		case EventoDesalojo, EventoSwapSalida, EventoSwapEntrada:
+			salida = append(salida, eventoChrome{
+				Nombre:  evento.Tipo,
+				Fase:    "i",
+				Marca:   microsegundos(evento.Marca),
+				Proceso: grupoChromeProcesos,
+				Hilo:    hiloDe(grupoChromeProcesos, fmt.Sprintf("PID %d", evento.PID)),
+				Alcance: "t",
+				Args:    map[string]interface{}{"recurso": evento.Recurso, "detalle": evento.Detalle},
+			})
+		}
+	}
+
+	return json.MarshalIndent(map[string]interface{}{"traceEvents": salida}, "", "  ")
+}
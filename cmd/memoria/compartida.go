@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+// Un segmento compartido es un conjunto de marcos con nombre que varios procesos mapean en su espacio
+// lógico. Los marcos son del segmento y no de los procesos: no figuran en marcosAsignadosPorProceso,
+// así que no se bajan a SWAP al suspender (quedan presentes para el resto de los procesos) ni se
+// liberan al finalizar. Se liberan cuando se desadjunta el último proceso
+
+// SegmentoCompartido es un segmento de memoria compartida identificado por nombre
+type SegmentoCompartido struct {
+	Nombre   string
+	Tamanio  int
+	Marcos   []int
+	Adjuntos map[int]int // PID -> primera página lógica donde está mapeado
+}
+
+// segmentosCompartidos se protege con memoriaGeneralMutex, igual que las tablas de páginas
+var segmentosCompartidos = make(map[string]*SegmentoCompartido)
+
+// adjuntarSegmento mapea el segmento en el proceso a partir de dirLogica (alineada a página). Si el
+// segmento no existe lo crea con el tamaño pedido; si existe se usa su tamaño original
+func adjuntarSegmento(pid int, nombre string, tamanio int, dirLogica int) (*SegmentoCompartido, error) {
+	memoriaGeneralMutex.Lock()
+	defer memoriaGeneralMutex.Unlock()
+
+	tabla, existe := tablasPaginas[pid]
+	if !existe {
+		return nil, fmt.Errorf("el proceso %d no existe en memoria", pid)
+	}
+	if dirLogica < 0 || dirLogica%config.PageSize != 0 {
+		return nil, fmt.Errorf("la dirección %d no está alineada a página (%d bytes)", dirLogica, config.PageSize)
+	}
+
+	segmento, existeSegmento := segmentosCompartidos[nombre]
+	if existeSegmento {
+		if _, adjunto := segmento.Adjuntos[pid]; adjunto {
+			return nil, fmt.Errorf("el proceso %d ya tiene adjuntado el segmento %s", pid, nombre)
+		}
+	} else if tamanio <= 0 {
+		return nil, fmt.Errorf("tamaño inválido para crear el segmento %s: %d", nombre, tamanio)
+	}
+
+	paginaBase := dirLogica / config.PageSize
+	numPaginas := calcularNumeroPaginas(tamanio)
+	if existeSegmento {
+		numPaginas = len(segmento.Marcos)
+	}
+	for i := 0; i < numPaginas; i++ {
+		if entrada := entradaDePagina(tabla, paginaBase+i); entrada != nil && entrada.Valido {
+			return nil, fmt.Errorf("la página %d del proceso %d ya está mapeada", paginaBase+i, pid)
+		}
+	}
+
+	if !existeSegmento {
+		marcos, err := reservarMarcosCompartidos(numPaginas)
+		if err != nil {
+			return nil, err
+		}
+		segmento = &SegmentoCompartido{
+			Nombre:   nombre,
+			Tamanio:  tamanio,
+			Marcos:   marcos,
+			Adjuntos: make(map[int]int),
+		}
+		segmentosCompartidos[nombre] = segmento
+		utils.InfoLog.Info(fmt.Sprintf("## Segmento compartido creado - Nombre: %s - Tamaño: %d - Marcos: %v", nombre, tamanio, marcos))
+	}
+
+	for i, marco := range segmento.Marcos {
+		actualizarTablaPaginas(pid, tabla, paginaBase+i, marco, 1)
+	}
+	segmento.Adjuntos[pid] = paginaBase
+
+	utils.InfoLog.Info(fmt.Sprintf("## PID: %d - Segmento compartido adjuntado - Nombre: %s - Dirección: %d - Procesos: %d",
+		pid, nombre, dirLogica, len(segmento.Adjuntos)))
+	return segmento, nil
+}
+
+// desadjuntarSegmento quita el mapeo del segmento del proceso y, si era el último, libera sus marcos
+func desadjuntarSegmento(pid int, nombre string) error {
+	memoriaGeneralMutex.Lock()
+	defer memoriaGeneralMutex.Unlock()
+
+	segmento, existe := segmentosCompartidos[nombre]
+	if !existe {
+		return fmt.Errorf("no existe el segmento compartido %s", nombre)
+	}
+	if _, adjunto := segmento.Adjuntos[pid]; !adjunto {
+		return fmt.Errorf("el proceso %d no tiene adjuntado el segmento %s", pid, nombre)
+	}
+
+	quitarSegmentoDeProceso(pid, segmento)
+	return nil
+}
+
+// desadjuntarSegmentosDeProceso desadjunta todos los segmentos del proceso antes de liberar su memoria
+func desadjuntarSegmentosDeProceso(pid int) {
+	memoriaGeneralMutex.Lock()
+	defer memoriaGeneralMutex.Unlock()
+
+	for _, segmento := range segmentosCompartidos {
+		if _, adjunto := segmento.Adjuntos[pid]; adjunto {
+			quitarSegmentoDeProceso(pid, segmento)
+		}
+	}
+}
+
+// quitarSegmentoDeProceso invalida las páginas del segmento en el proceso y decrementa sus referencias.
+// Requiere memoriaGeneralMutex tomado
+func quitarSegmentoDeProceso(pid int, segmento *SegmentoCompartido) {
+	paginaBase := segmento.Adjuntos[pid]
+	if tabla, existe := tablasPaginas[pid]; existe {
+		for i := range segmento.Marcos {
+			if entrada := entradaDePagina(tabla, paginaBase+i); entrada != nil {
+				entrada.Valido = false
+				entrada.Presente = false
+			}
+		}
+	}
+	delete(segmento.Adjuntos, pid)
+
+	utils.InfoLog.Info(fmt.Sprintf("## PID: %d - Segmento compartido desadjuntado - Nombre: %s - Procesos: %d",
+		pid, segmento.Nombre, len(segmento.Adjuntos)))
+
+	if len(segmento.Adjuntos) > 0 {
+		return
+	}
+
+	for _, marco := range segmento.Marcos {
+		inicio := marco * config.PageSize
+		for i := inicio; i < inicio+config.PageSize && i < len(memoriaPrincipal); i++ {
+			memoriaPrincipal[i] = 0
+		}
+		marcosLibres[marco] = true
+	}
+	delete(segmentosCompartidos, segmento.Nombre)
+
+	utils.InfoLog.Info(fmt.Sprintf("## Segmento compartido destruido - Nombre: %s - Marcos liberados: %d", segmento.Nombre, len(segmento.Marcos)))
+}
+
+// marcoCompartido indica si el marco es de un segmento compartido. Se informa a la CPU al traducir
+// para que no cachee la página: otro proceso puede escribirla en cualquier momento
+func marcoCompartido(marco int) bool {
+	memoriaGeneralMutex.Lock()
+	defer memoriaGeneralMutex.Unlock()
+
+	for _, segmento := range segmentosCompartidos {
+		for _, m := range segmento.Marcos {
+			if m == marco {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reservarMarcosCompartidos toma marcos libres sin asignarlos a ningún proceso. Requiere memoriaGeneralMutex tomado
+func reservarMarcosCompartidos(cantidad int) ([]int, error) {
+	if libres := contarMarcosLibres(); libres < cantidad {
+		return nil, fmt.Errorf("no hay suficientes marcos libres para el segmento: necesita %d, disponibles %d", cantidad, libres)
+	}
+
+	marcos := []int{}
+	for i, libre := range marcosLibres {
+		if len(marcos) == cantidad {
+			break
+		}
+		if libre {
+			marcosLibres[i] = false
+			marcos = append(marcos, i)
+		}
+	}
+	return marcos, nil
+}
+
+// entradaDePagina devuelve la entrada de último nivel de una página sin crear tablas intermedias,
+// o nil si alguno de los niveles no existe
+func entradaDePagina(tabla *TablaPaginas, numPagina int) *EntradaTabla {
+	for nivel := 1; tabla != nil; nivel++ {
+		indice := calcularIndiceEnNivel(numPagina, nivel)
+		if indice >= len(tabla.Entradas) {
+			return nil
+		}
+		if nivel == config.NumberOfLevels {
+			return &tabla.Entradas[indice]
+		}
+		if !tabla.Entradas[indice].Valido {
+			return nil
+		}
+		tabla = obtenerTablaSiguienteNivel(tabla.Entradas[indice].Direccion)
+	}
+	return nil
+}
+
+func handlerAdjuntarSegmento(msg *utils.Mensaje) (interface{}, error) {
+	datos, ok := msg.Datos.(map[string]interface{})
+	if !ok {
+		utils.ErrorLog.Error("Formato de datos incorrecto", "datos", msg.Datos)
+		return map[string]interface{}{"error": "Formato de datos incorrecto"}, nil
+	}
+
+	pid, okPid := datos["pid"].(float64)
+	nombre, okNombre := datos["nombre"].(string)
+	tamanio, okTamanio := datos["tamanio"].(float64)
+	direccion, okDireccion := datos["direccion"].(float64)
+	if !okPid || !okNombre || !okTamanio || !okDireccion || nombre == "" {
+		utils.ErrorLog.Error("Datos de segmento compartido incorrectos", "datos", datos)
+		return map[string]interface{}{"error": "PID, nombre, tamaño o dirección no proporcionados o con formato incorrecto"}, nil
+	}
+
+	segmento, err := adjuntarSegmento(int(pid), nombre, int(tamanio), int(direccion))
+	if err != nil {
+		utils.ErrorLog.Error("Error adjuntando segmento compartido", "pid", int(pid), "nombre", nombre, "error", err)
+		return map[string]interface{}{"error": err.Error()}, nil
+	}
+
+	return map[string]interface{}{
+		"status":  "OK",
+		"tamanio": segmento.Tamanio,
+	}, nil
+}
+
+func handlerDesadjuntarSegmento(msg *utils.Mensaje) (interface{}, error) {
+	datos, ok := msg.Datos.(map[string]interface{})
+	if !ok {
+		utils.ErrorLog.Error("Formato de datos incorrecto", "datos", msg.Datos)
+		return map[string]interface{}{"error": "Formato de datos incorrecto"}, nil
+	}
+
+	pid, okPid := datos["pid"].(float64)
+	nombre, okNombre := datos["nombre"].(string)
+	if !okPid || !okNombre {
+		utils.ErrorLog.Error("PID o nombre de segmento no proporcionado", "datos", datos)
+		return map[string]interface{}{"error": "PID o nombre no proporcionado o formato incorrecto"}, nil
+	}
+
+	if err := desadjuntarSegmento(int(pid), nombre); err != nil {
+		utils.ErrorLog.Error("Error desadjuntando segmento compartido", "pid", int(pid), "nombre", nombre, "error", err)
+		return map[string]interface{}{"error": err.Error()}, nil
+	}
+
+	return map[string]interface{}{
+		"status": "OK",
+	}, nil
+}
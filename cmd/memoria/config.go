@@ -2,18 +2,22 @@ package main
 
 // MemoryConfig representa la configuración específica del módulo Memoria
 type MemoryConfig struct {
-	IPMemory       string `json:"IP_MEMORIA"`
-	PortMemory     int    `json:"PUERTO_MEMORIA"`
-	LogLevel       string `json:"LOG_LEVEL"`
-	MemorySize     int    `json:"TAM_MEMORIA"`        // Tamaño de la memoria en bytes
-	PageSize       int    `json:"TAM_PAGINA"`         // Tamaño de página en bytes
-	NumberOfLevels int    `json:"CANTIDAD_NIVELES"`   // Número de niveles de tabla de páginas
-	EntriesPerPage int    `json:"ENTRADAS_POR_TABLA"` // Entradas por página
-	MemoryDelay    int    `json:"RETARDO_MEMORIA"`    // Retardo de acceso a memoria
-	SwapDelay      int    `json:"RETARDO_SWAP"`       // Retardo de acceso a swap
-	SwapfilePath   string `json:"SWAPFILE_PATH"`      // Ruta al archivo de swap
-	DumpPath       string `json:"DUMP_PATH"`          // Ruta para los archivos de dump
-	ScriptsPath    string `json:"SCRIPTS_PATH"`
+	IPMemory       string  `json:"IP_MEMORIA"`
+	PortMemory     int     `json:"PUERTO_MEMORIA"`
+	LogLevel       string  `json:"LOG_LEVEL"`
+	MemorySize     int     `json:"TAM_MEMORIA"`        // Tamaño de la memoria en bytes
+	PageSize       int     `json:"TAM_PAGINA"`         // Tamaño de página en bytes
+	NumberOfLevels int     `json:"CANTIDAD_NIVELES"`   // Número de niveles de tabla de páginas
+	EntriesPerPage int     `json:"ENTRADAS_POR_TABLA"` // Entradas por página
+	MemoryDelay    int     `json:"RETARDO_MEMORIA"`    // Retardo de acceso a memoria
+	SwapDelay      int     `json:"RETARDO_SWAP"`       // Retardo de acceso a swap
+	SwapfilePath   string  `json:"SWAPFILE_PATH"`      // Ruta al archivo de swap
+	DumpPath       string  `json:"DUMP_PATH"`          // Ruta para los archivos de dump
+	ScriptsPath    string  `json:"SCRIPTS_PATH"`
+	ClockMode      string  `json:"MODO_RELOJ,omitempty"`   // REAL, ESCALADO o DISCRETO
+	ClockFactor    float64 `json:"FACTOR_RELOJ,omitempty"` // Aceleración del modo ESCALADO
+	IPKernel       string  `json:"IP_KERNEL,omitempty"`    // Kernel que lleva el reloj del modo DISCRETO
+	PortKernel     int     `json:"PUERTO_KERNEL,omitempty"`
 }
 
 var config *MemoryConfig
@@ -44,6 +44,28 @@ func traducirDireccion(pid int, dirLogica int) (int, error) {
 	return dirFisica, nil
 }
 
+// tramoFisico es la parte de un acceso que cae dentro de una misma página
+type tramoFisico struct {
+	DirFisica int
+	Tamanio   int
+}
+
+// traducirRango traduce un acceso lógico de varios bytes en un tramo físico por cada página que toca
+func traducirRango(pid int, dirLogica int, tamanio int) ([]tramoFisico, error) {
+	tramos := []tramoFisico{}
+	for tamanio > 0 {
+		dirFisica, err := traducirDireccion(pid, dirLogica)
+		if err != nil {
+			return nil, err
+		}
+		enPagina := min(tamanio, config.PageSize-dirLogica%config.PageSize)
+		tramos = append(tramos, tramoFisico{DirFisica: dirFisica, Tamanio: enPagina})
+		dirLogica += enPagina
+		tamanio -= enPagina
+	}
+	return tramos, nil
+}
+
 // Calcula el número de páginas necesarias para un tamaño dado
 func calcularNumeroPaginas(tamanio int) int {
 	numPaginas := (tamanio + config.PageSize - 1) / config.PageSize
@@ -42,14 +42,31 @@ func handlerObtenerInstruccion(msg *utils.Mensaje) (interface{}, error) {
 	pidInt := int(pid)
 	pcInt := int(pc)
 
-	utils.InfoLog.Info("Solicitud de instrucción", "pid", pidInt, "pc", pcInt)
-
-	// Verificar si hay instrucciones para el PID
-	instruccionesMutex.RLock()
-	instrucciones, existe := instruccionesPorProceso[pidInt]
-	instruccionesMutex.RUnlock()
+	// El TID es opcional: sin él (o con 0) se ejecuta el hilo principal del proceso
+	tidInt := 0
+	if tid, hayTID := datos["tid"].(float64); hayTID {
+		tidInt = int(tid)
+	}
+
+	utils.InfoLog.Info("Solicitud de instrucción", "pid", pidInt, "tid", tidInt, "pc", pcInt)
+
+	// Verificar si hay instrucciones para el PID (o para el hilo)
+	var instrucciones []string
+	existe := false
+	if tidInt > 0 {
+		if instrucciones, existe = instruccionesDeHilo(pidInt, tidInt); !existe {
+			utils.ErrorLog.Error("Hilo sin instrucciones cargadas", "pid", pidInt, "tid", tidInt)
+			return map[string]interface{}{
+				"error": fmt.Sprintf("No hay instrucciones para el hilo %d del PID %d", tidInt, pidInt),
+			}, nil
+		}
+	} else {
+		instruccionesMutex.RLock()
+		instrucciones, existe = instruccionesPorProceso[pidInt]
+		instruccionesMutex.RUnlock()
+	}
 
-	if !existe || len(instrucciones) == 0 {
+	if tidInt == 0 && (!existe || len(instrucciones) == 0) {
 		instruccionesMutex.Lock()
 		// Doble verificación
 		if instrucciones, existe = instruccionesPorProceso[pidInt]; !existe {
@@ -89,7 +106,7 @@ func handlerObtenerInstruccion(msg *utils.Mensaje) (interface{}, error) {
 	// Actualizar métricas
 	actualizarMetricasInstruccion(pidInt)
 
-	utils.InfoLog.Info("Instrucción entregada", "pid", pidInt, "pc", pcInt, "instruccion", instruccion)
+	utils.InfoLog.Info("Instrucción entregada", "pid", pidInt, "tid", tidInt, "pc", pcInt, "instruccion", instruccion)
 
 	return map[string]interface{}{
 		"status":      "OK",
@@ -187,6 +204,9 @@ func handlerFinalizarProceso(msg *utils.Mensaje) (interface{}, error) {
 		utils.ErrorLog.Error("Error creando dump final", "pid", pidInt, "error", err)
 	}
 
+	// Los segmentos compartidos se desadjuntan antes: sus marcos no son del proceso
+	desadjuntarSegmentosDeProceso(pidInt)
+
 	// Liberar memoria del proceso
 	if err := liberarMemoriaProceso(pidInt); err != nil {
 		utils.ErrorLog.Error("Error liberando memoria", "pid", pidInt, "error", err)
@@ -212,6 +232,7 @@ func handlerFinalizarProceso(msg *utils.Mensaje) (interface{}, error) {
 	instruccionesMutex.Lock()
 	delete(instruccionesPorProceso, pidInt)
 	instruccionesMutex.Unlock()
+	descartarHilosDeProceso(pidInt)
 
 	utils.InfoLog.Info("Proceso finalizado correctamente", "pid", pidInt)
 
@@ -229,45 +250,37 @@ func handlerLeerMemoria(msg *utils.Mensaje) (interface{}, error) {
 	}
 	pidInt := int(pid)
 
-	// Dirección puede ser física o lógica
-	dirFisica, ok := datos["direccion_fisica"].(float64)
-	if !ok {
-		dirLogica, ok := datos["direccion_logica"].(float64)
-		if !ok {
-			utils.ErrorLog.Error("Dirección no proporcionada", "datos", datos)
-			return map[string]interface{}{"error": "Dirección no proporcionada o formato incorrecto"}, nil
-		}
-
-		dirFisicaInt, err := traducirDireccion(pidInt, int(dirLogica))
-		if err != nil {
-			utils.ErrorLog.Error("Error traduciendo dirección", "pid", pidInt, "dir_logica", int(dirLogica), "error", err)
-			return map[string]interface{}{"error": fmt.Sprintf("Error traduciendo dirección: %v", err)}, nil
-		}
-		dirFisica = float64(dirFisicaInt)
-	}
-
 	tamanio, ok := datos["tamanio"].(float64)
 	if !ok {
 		tamanio = 1
 	}
 
-	// Verificar límites
-	if int(dirFisica) < 0 || int(dirFisica)+int(tamanio) > len(memoriaPrincipal) {
-		utils.ErrorLog.Error("Dirección fuera de rango", "pid", pidInt, "dir_fisica", int(dirFisica), "tamanio", int(tamanio))
-		return map[string]interface{}{"error": "Dirección fuera de rango"}, nil
+	// Dirección puede ser física o lógica (una lógica puede abarcar varias páginas)
+	tramos, respuestaError := tramosDelAcceso(pidInt, datos, int(tamanio))
+	if respuestaError != nil {
+		return respuestaError, nil
 	}
 
-	// Leer de memoria
-	valor := memoriaPrincipal[int(dirFisica):int(dirFisica+tamanio)]
+	valor := []byte{}
+	for _, tramo := range tramos {
+		// Verificar límites
+		if tramo.DirFisica < 0 || tramo.DirFisica+tramo.Tamanio > len(memoriaPrincipal) {
+			utils.ErrorLog.Error("Dirección fuera de rango", "pid", pidInt, "dir_fisica", tramo.DirFisica, "tamanio", tramo.Tamanio)
+			return map[string]interface{}{"error": "Dirección fuera de rango"}, nil
+		}
+
+		// Leer de memoria
+		valor = append(valor, memoriaPrincipal[tramo.DirFisica:tramo.DirFisica+tramo.Tamanio]...)
+
+		// Log obligatorio
+		utils.InfoLog.Info(fmt.Sprintf("## PID: %d - Lectura - Dir Física: %d - Tamaño: %d",
+			pidInt, tramo.DirFisica, tramo.Tamanio))
+	}
 
 	// Actualizar métricas
 	actualizarMetricasLectura(pidInt)
 
-	// Log obligatorio
-	utils.InfoLog.Info(fmt.Sprintf("## PID: %d - Lectura - Dir Física: %d - Tamaño: %d",
-		pidInt, int(dirFisica), int(tamanio)))
-
-	utils.InfoLog.Info("Lectura de memoria realizada", "pid", pidInt, "dir_fisica", int(dirFisica), "tamanio", int(tamanio))
+	utils.InfoLog.Info("Lectura de memoria realizada", "pid", pidInt, "tramos", len(tramos), "tamanio", len(valor))
 
 	return map[string]interface{}{
 		"status": "OK",
@@ -284,52 +297,68 @@ func handlerEscribirMemoria(msg *utils.Mensaje) (interface{}, error) {
 	}
 	pidInt := int(pid)
 
-	// Dirección puede ser física o lógica
-	dirFisica, ok := datos["direccion_fisica"].(float64)
-	if !ok {
-		dirLogica, ok := datos["direccion_logica"].(float64)
-		if !ok {
-			utils.ErrorLog.Error("Dirección no proporcionada", "datos", datos)
-			return map[string]interface{}{"error": "Dirección no proporcionada o formato incorrecto"}, nil
-		}
-
-		dirFisicaInt, err := traducirDireccion(pidInt, int(dirLogica))
-		if err != nil {
-			utils.ErrorLog.Error("Error traduciendo dirección", "pid", pidInt, "dir_logica", int(dirLogica), "error", err)
-			return map[string]interface{}{"error": fmt.Sprintf("Error traduciendo dirección: %v", err)}, nil
-		}
-		dirFisica = float64(dirFisicaInt)
-	}
-
 	valor, ok := datos["valor"].(string)
 	if !ok {
 		utils.ErrorLog.Error("Valor no proporcionado", "datos", datos)
 		return map[string]interface{}{"error": "Valor no proporcionado o formato incorrecto"}, nil
 	}
 
-	// Verificar límites
-	if int(dirFisica) < 0 || int(dirFisica)+len(valor) > len(memoriaPrincipal) {
-		utils.ErrorLog.Error("Dirección fuera de rango para escritura", "pid", pidInt, "dir_fisica", int(dirFisica), "tamanio_valor", len(valor))
-		return map[string]interface{}{"error": "Dirección fuera de rango"}, nil
+	// Dirección puede ser física o lógica (una lógica puede abarcar varias páginas)
+	tramos, respuestaError := tramosDelAcceso(pidInt, datos, len(valor))
+	if respuestaError != nil {
+		return respuestaError, nil
+	}
+
+	// Verificar límites antes de escribir para no dejar escrituras a medias
+	for _, tramo := range tramos {
+		if tramo.DirFisica < 0 || tramo.DirFisica+tramo.Tamanio > len(memoriaPrincipal) {
+			utils.ErrorLog.Error("Dirección fuera de rango para escritura", "pid", pidInt, "dir_fisica", tramo.DirFisica, "tamanio_valor", tramo.Tamanio)
+			return map[string]interface{}{"error": "Dirección fuera de rango"}, nil
+		}
 	}
 
 	// Escribir en memoria
-	copy(memoriaPrincipal[int(dirFisica):int(dirFisica)+len(valor)], []byte(valor))
+	escritos := 0
+	for _, tramo := range tramos {
+		copy(memoriaPrincipal[tramo.DirFisica:tramo.DirFisica+tramo.Tamanio], valor[escritos:escritos+tramo.Tamanio])
+		escritos += tramo.Tamanio
+
+		// Log obligatorio
+		utils.InfoLog.Info(fmt.Sprintf("## PID: %d - Escritura - Dir Física: %d - Tamaño: %d",
+			pidInt, tramo.DirFisica, tramo.Tamanio))
+	}
 
 	// Actualizar métricas
 	actualizarMetricasEscritura(pidInt)
 
-	// Log obligatorio
-	utils.InfoLog.Info(fmt.Sprintf("## PID: %d - Escritura - Dir Física: %d - Tamaño: %d",
-		pidInt, int(dirFisica), len(valor)))
-
-	utils.InfoLog.Info("Escritura en memoria realizada", "pid", pidInt, "dir_fisica", int(dirFisica), "tamanio", len(valor))
+	utils.InfoLog.Info("Escritura en memoria realizada", "pid", pidInt, "tramos", len(tramos), "tamanio", len(valor))
 
 	return map[string]interface{}{
 		"status": "OK",
 	}, nil
 }
 
+// tramosDelAcceso arma los tramos físicos de una lectura o escritura. Con direccion_fisica el acceso es
+// contiguo; con direccion_logica se traduce página por página. Devuelve la respuesta de error si falla
+func tramosDelAcceso(pid int, datos map[string]interface{}, tamanio int) ([]tramoFisico, map[string]interface{}) {
+	if dirFisica, ok := datos["direccion_fisica"].(float64); ok {
+		return []tramoFisico{{DirFisica: int(dirFisica), Tamanio: tamanio}}, nil
+	}
+
+	dirLogica, ok := datos["direccion_logica"].(float64)
+	if !ok {
+		utils.ErrorLog.Error("Dirección no proporcionada", "datos", datos)
+		return nil, map[string]interface{}{"error": "Dirección no proporcionada o formato incorrecto"}
+	}
+
+	tramos, err := traducirRango(pid, int(dirLogica), tamanio)
+	if err != nil {
+		utils.ErrorLog.Error("Error traduciendo dirección", "pid", pid, "dir_logica", int(dirLogica), "error", err)
+		return nil, map[string]interface{}{"error": fmt.Sprintf("Error traduciendo dirección: %v", err)}
+	}
+	return tramos, nil
+}
+
 func handlerObtenerMarco(msg *utils.Mensaje) (interface{}, error) {
 	datos := msg.Datos.(map[string]interface{})
 	pid, ok := datos["pid"].(float64)
@@ -368,8 +397,9 @@ func handlerObtenerMarco(msg *utils.Mensaje) (interface{}, error) {
 	utils.InfoLog.Info("Marco obtenido", "pid", pidInt, "pagina", int(numPagina), "marco", marco)
 
 	return map[string]interface{}{
-		"status": "OK",
-		"marco":  marco,
+		"status":     "OK",
+		"marco":      marco,
+		"compartida": marcoCompartido(marco) || procesoConHilos(pidInt),
 	}, nil
 }
 
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/sisoputnfrba/tp-2025-1c-LosCuervosXeneizes/utils"
+)
+
+// Los hilos secundarios comparten la tabla de páginas del proceso (se accede con su PID) y solo
+// tienen instrucciones propias. El hilo principal (TID 0) usa las instrucciones del proceso
+
+// claveHilo arma la clave de instruccionesPorHilo, con el mismo formato que mapaSwap
+func claveHilo(pid, tid int) string {
+	return fmt.Sprintf("%d-%d", pid, tid)
+}
+
+// instruccionesDeHilo devuelve las instrucciones cargadas para un hilo secundario
+func instruccionesDeHilo(pid, tid int) ([]string, bool) {
+	instruccionesMutex.RLock()
+	defer instruccionesMutex.RUnlock()
+	instrucciones, existe := instruccionesPorHilo[claveHilo(pid, tid)]
+	return instrucciones, existe
+}
+
+func handlerCrearHilo(msg *utils.Mensaje) (interface{}, error) {
+	datos, ok := msg.Datos.(map[string]interface{})
+	if !ok {
+		utils.ErrorLog.Error("Formato de datos incorrecto", "datos", msg.Datos)
+		return map[string]interface{}{"error": "Formato de datos incorrecto"}, nil
+	}
+
+	pid, okPid := datos["pid"].(float64)
+	tid, okTid := datos["tid"].(float64)
+	archivoOrigen, okArchivo := datos["archivo"].(string)
+	if !okPid || !okTid || !okArchivo || tid <= 0 {
+		utils.ErrorLog.Error("Datos de hilo incorrectos", "datos", datos)
+		return map[string]interface{}{"error": "PID, TID o archivo no proporcionados o con formato incorrecto"}, nil
+	}
+	pidInt, tidInt := int(pid), int(tid)
+
+	utils.InfoLog.Info("Solicitud de creación de hilo", "pid", pidInt, "tid", tidInt, "archivo", archivoOrigen)
+
+	if _, existe := tablasPaginas[pidInt]; !existe {
+		utils.ErrorLog.Error("Hilo de un proceso inexistente", "pid", pidInt, "tid", tidInt)
+		return map[string]interface{}{"error": fmt.Sprintf("el proceso %d no existe en memoria", pidInt)}, nil
+	}
+
+	destino := filepath.Join(config.ScriptsPath, fmt.Sprintf("%d-%d.txt", pidInt, tidInt))
+	if err := copiarPseudocodigo(archivoOrigen, destino); err != nil {
+		utils.ErrorLog.Error("Error copiando pseudocódigo del hilo", "archivo_origen", archivoOrigen, "destino", destino, "error", err)
+		return map[string]interface{}{"error": err.Error()}, nil
+	}
+
+	instrucciones, err := leerPseudocodigo(destino)
+	if err != nil {
+		utils.ErrorLog.Error("Error cargando instrucciones del hilo", "pid", pidInt, "tid", tidInt, "error", err)
+		return map[string]interface{}{"error": err.Error()}, nil
+	}
+
+	instruccionesMutex.Lock()
+	instruccionesPorHilo[claveHilo(pidInt, tidInt)] = instrucciones
+	instruccionesMutex.Unlock()
+
+	utils.InfoLog.Info(fmt.Sprintf("## PID: %d - Hilo Creado - TID: %d - Tamaño: %d", pidInt, tidInt, len(instrucciones)))
+
+	return map[string]interface{}{
+		"status": "OK",
+	}, nil
+}
+
+func handlerFinalizarHilo(msg *utils.Mensaje) (interface{}, error) {
+	datos := msg.Datos.(map[string]interface{})
+	pid, okPid := datos["pid"].(float64)
+	tid, okTid := datos["tid"].(float64)
+	if !okPid || !okTid {
+		utils.ErrorLog.Error("PID o TID no proporcionado", "datos", datos)
+		return map[string]interface{}{"error": "PID o TID no proporcionado o formato incorrecto"}, nil
+	}
+	pidInt, tidInt := int(pid), int(tid)
+
+	instruccionesMutex.Lock()
+	delete(instruccionesPorHilo, claveHilo(pidInt, tidInt))
+	instruccionesMutex.Unlock()
+
+	utils.InfoLog.Info(fmt.Sprintf("## PID: %d - Hilo Destruido - TID: %d", pidInt, tidInt))
+
+	return map[string]interface{}{
+		"status": "OK",
+	}, nil
+}
+
+// descartarHilosDeProceso borra las instrucciones de todos los hilos secundarios del proceso
+func descartarHilosDeProceso(pid int) {
+	prefijo := fmt.Sprintf("%d-", pid)
+
+	instruccionesMutex.Lock()
+	defer instruccionesMutex.Unlock()
+	for clave := range instruccionesPorHilo {
+		if strings.HasPrefix(clave, prefijo) {
+			delete(instruccionesPorHilo, clave)
+		}
+	}
+}
+
+// procesoConHilos indica si el proceso tiene hilos secundarios cargados. Sus páginas se informan a la CPU
+// como compartidas: dos hilos pueden ejecutar en CPUs distintas sobre la misma memoria
+func procesoConHilos(pid int) bool {
+	prefijo := fmt.Sprintf("%d-", pid)
+
+	instruccionesMutex.RLock()
+	defer instruccionesMutex.RUnlock()
+	for clave := range instruccionesPorHilo {
+		if strings.HasPrefix(clave, prefijo) {
+			return true
+		}
+	}
+	return false
+}
@@ -55,6 +55,11 @@ func inicializarModulo() {
 	utils.InicializarLogger(config.LogLevel, "Memoria")
 	utils.InfoLog.Info("Configuración cargada", "nivel_log", config.LogLevel, "config_path", rutaConfig)
 
+	if err := utils.ConfigurarRelojCompartido(config.ClockMode, config.ClockFactor, config.IPKernel, config.PortKernel, config.IPMemory, config.PortMemory); err != nil {
+		utils.ErrorLog.Error("Configuración de reloj inválida", "error", err)
+		os.Exit(1)
+	}
+
 	// Verificar directorio de dumps
 	if err := os.MkdirAll(config.DumpPath, 0755); err != nil {
 		utils.InfoLog.Warn("No se pudo crear directorio para dumps", "error", err)
@@ -68,6 +73,7 @@ func inicializarModulo() {
 
 	// Inicializar mapa de instrucciones
 	instruccionesPorProceso = make(map[int][]string)
+	instruccionesPorHilo = make(map[string][]string)
 	utils.InfoLog.Info("Mapa de instrucciones inicializado")
 
 	// Registrar handlers
@@ -93,7 +99,11 @@ func registrarHandlers() {
 	modulo.RegistrarHandler(strconv.Itoa(utils.MensajeObtenerMarco), "default", handlerObtenerMarco)
 	modulo.RegistrarHandler(strconv.Itoa(utils.MensajeSuspenderProceso), "default", handlerSuspenderProceso)
 	modulo.RegistrarHandler(strconv.Itoa(utils.MensajeDessuspenderProceso), "default", handlerDessuspenderProceso)
+	modulo.RegistrarHandler(strconv.Itoa(utils.MensajeCrearHilo), "default", handlerCrearHilo)
+	modulo.RegistrarHandler(strconv.Itoa(utils.MensajeFinalizarHilo), "default", handlerFinalizarHilo)
 	modulo.RegistrarHandler(strconv.Itoa(utils.MensajeMemoryDump), "default", handlerMemoryDump)
+	modulo.RegistrarHandler(strconv.Itoa(utils.MensajeAdjuntarSegmento), "default", handlerAdjuntarSegmento)
+	modulo.RegistrarHandler(strconv.Itoa(utils.MensajeDesadjuntarSegmento), "default", handlerDesadjuntarSegmento)
 
 	utils.InfoLog.Info("Handlers registrados correctamente")
 }
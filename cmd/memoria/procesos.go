@@ -19,24 +19,12 @@ func cargarInstrucciones(pid int) error {
 	rutaArchivo := filepath.Clean(filepath.Join(config.ScriptsPath, fmt.Sprintf("%d.txt", pid)))
 	utils.InfoLog.Info("Ruta del archivo", "pid", pid, "archivo", rutaArchivo)
 
-	// Leer el archivo línea por línea
-	contenido, err := os.ReadFile(rutaArchivo)
+	instruccionesFiltradas, err := leerPseudocodigo(rutaArchivo)
 	if err != nil {
 		utils.ErrorLog.Error("Error leyendo archivo de pseudocódigo", "pid", pid, "archivo", rutaArchivo, "error", err)
 		return fmt.Errorf("error al leer el archivo de pseudocódigo para PID %d: %v", pid, err)
 	}
 
-	// Dividir el contenido en líneas (instrucciones)
-	instrucciones := strings.Split(string(contenido), "\n")
-
-	// Filtrar líneas vacías
-	instruccionesFiltradas := []string{}
-	for _, instruccion := range instrucciones {
-		if strings.TrimSpace(instruccion) != "" {
-			instruccionesFiltradas = append(instruccionesFiltradas, instruccion)
-		}
-	}
-
 	utils.InfoLog.Info("Instrucciones procesadas", "pid", pid, "total_instrucciones", len(instruccionesFiltradas))
 
 	instruccionesMutex.Lock()
@@ -51,6 +39,23 @@ func cargarInstrucciones(pid int) error {
 	return nil
 }
 
+// leerPseudocodigo devuelve las instrucciones (líneas no vacías) de un archivo de pseudocódigo
+func leerPseudocodigo(rutaArchivo string) ([]string, error) {
+	contenido, err := os.ReadFile(rutaArchivo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dividir el contenido en líneas (instrucciones) y filtrar las vacías
+	instrucciones := []string{}
+	for _, instruccion := range strings.Split(string(contenido), "\n") {
+		if strings.TrimSpace(instruccion) != "" {
+			instrucciones = append(instrucciones, instruccion)
+		}
+	}
+	return instrucciones, nil
+}
+
 func copiarPseudocodigo(origen string, destino string) error {
 	utils.InfoLog.Info("Copiando archivo de pseudocódigo", "origen", origen, "destino", destino)
 
@@ -77,7 +82,8 @@ func copiarPseudocodigo(origen string, destino string) error {
 	return nil
 }
 
-// suspenderProceso guarda todas las páginas de un proceso en SWAP y libera sus marcos
+// suspenderProceso guarda todas las páginas de un proceso en SWAP y libera sus marcos. Las páginas de
+// segmentos compartidos no están en marcosAsignadosPorProceso: quedan presentes para los demás procesos
 func suspenderProceso(pid int) error {
 	utils.InfoLog.Info("Iniciando suspensión de proceso", "pid", pid)
 
@@ -40,6 +40,7 @@ type MetricasProceso struct {
 // Variables globales
 var memoriaPrincipal []byte
 var instruccionesPorProceso map[int][]string
+var instruccionesPorHilo map[string][]string // key: "PID-TID", hilos secundarios creados por THREAD_CREATE
 var instruccionesMutex sync.RWMutex
 var tablasPaginas map[int]*TablaPaginas     // Mapa de PID a tabla de páginas de primer nivel
 var marcosLibres []bool                     // true = libre, false = ocupado
@@ -28,11 +28,16 @@ type HTTPClient struct {
 
 // NewHTTPClient crea un nuevo cliente HTTP
 func NewHTTPClient(ip string, puerto int, nombre string) *HTTPClient {
+	return NewHTTPClientConTimeout(ip, puerto, nombre, 10*time.Second)
+}
+
+// NewHTTPClientConTimeout crea un cliente HTTP con un timeout propio (por ejemplo, para chequeos periódicos)
+func NewHTTPClientConTimeout(ip string, puerto int, nombre string, timeout time.Duration) *HTTPClient {
 	return &HTTPClient{
 		BaseURL: fmt.Sprintf("http://%s:%d", ip, puerto),
 		Nombre:  nombre,
 		client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout: timeout,
 		},
 	}
 }
@@ -51,15 +56,24 @@ func (c *HTTPClient) EnviarHTTPMensaje(tipo int, operacion string, datos interfa
 		return nil, fmt.Errorf("error al serializar mensaje: %v", err)
 	}
 
-	resp, err := c.client.Post(
-		fmt.Sprintf("%s/mensaje", c.BaseURL),
-		"application/json",
-		bytes.NewBuffer(jsonData),
-	)
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/mensaje", c.BaseURL), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("error al armar mensaje HTTP: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	marcarReloj(req.Header)
+
+	// El reloj compartido cuenta el mensaje en vuelo hasta que llega la respuesta (o falla el envío)
+	contarEnvio()
+	finEspera := esperando()
+	resp, err := c.client.Do(req)
+	finEspera()
+	contarRecepcion()
 	if err != nil {
-		return nil, fmt.Errorf("error al enviar mensaje HTTP: %v", err)
+		return nil, fmt.Errorf("error al enviar mensaje HTTP: %w", err)
 	}
 	defer resp.Body.Close()
+	sincronizarReloj(resp.Header)
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
@@ -87,7 +101,7 @@ func (c *HTTPClient) VerificarConexion() error {
 		return fmt.Errorf("estado inesperado al verificar conexión: %d", resp.StatusCode)
 	}
 
-	var result map[string]string
+	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return fmt.Errorf("error al decodificar respuesta de verificación: %v", err)
 	}
@@ -96,6 +110,26 @@ func (c *HTTPClient) VerificarConexion() error {
 	return nil
 }
 
+// Latido consulta /health como VerificarConexion pero sin loguear, para los chequeos periódicos.
+// Devuelve el estado que informa el módulo
+func (c *HTTPClient) Latido() (map[string]interface{}, error) {
+	resp, err := c.client.Get(fmt.Sprintf("%s/health", c.BaseURL))
+	if err != nil {
+		return nil, fmt.Errorf("sin respuesta de %s: %w", c.BaseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("estado inesperado en latido de %s: %d", c.BaseURL, resp.StatusCode)
+	}
+
+	var estado map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&estado); err != nil {
+		return nil, fmt.Errorf("latido inválido de %s: %w", c.BaseURL, err)
+	}
+	return estado, nil
+}
+
 // EnviarHTTPOperacion envía un mensaje de operación a través de HTTP
 func (c *HTTPClient) EnviarHTTPOperacion(operacion string, datos map[string]interface{}) (interface{}, error) {
 	return c.EnviarHTTPMensaje(MensajeOperacion, operacion, datos)
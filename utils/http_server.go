@@ -18,7 +18,11 @@ type HTTPServer struct {
 	Nombre   string
 	server   *http.Server
 	handlers map[int]HTTPHandlerFunc
+	rutas    map[string]http.HandlerFunc
 	Listener net.Listener
+
+	// EstadoSalud agrega datos propios del módulo a la respuesta de /health
+	EstadoSalud func() map[string]interface{}
 }
 
 // NewHTTPServer crea un nuevo servidor HTTP
@@ -28,6 +32,7 @@ func NewHTTPServer(ip string, puerto int, nombre string) *HTTPServer {
 		Puerto:   puerto,
 		Nombre:   nombre,
 		handlers: make(map[int]HTTPHandlerFunc),
+		rutas:    make(map[string]http.HandlerFunc),
 	}
 }
 
@@ -36,12 +41,32 @@ func (s *HTTPServer) RegisterHTTPHandler(tipoMensaje int, handler HTTPHandlerFun
 	s.handlers[tipoMensaje] = handler
 }
 
+// RegistrarRuta registra un manejador HTTP propio para un patrón (ej. "GET /admin/procesos/{pid}")
+func (s *HTTPServer) RegistrarRuta(patron string, handler http.HandlerFunc) {
+	s.rutas[patron] = handler
+}
+
+// ResponderJSON escribe una respuesta JSON con el código de estado indicado
+func ResponderJSON(w http.ResponseWriter, codigo int, respuesta interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(codigo)
+	json.NewEncoder(w).Encode(respuesta)
+}
+
 // Start inicia el servidor HTTP
 func (s *HTTPServer) Start() error {
 	mux := http.NewServeMux()
 
 	// Endpoint para recibir mensajes
 	mux.HandleFunc("/mensaje", func(w http.ResponseWriter, r *http.Request) {
+		// El mensaje queda en vuelo para el reloj compartido hasta que se envía la respuesta
+		contarRecepcion()
+		defer contarEnvio()
+		manejadoresActivos.Add(1)
+		defer manejadoresActivos.Add(-1)
+		sincronizarReloj(r.Header)
+		marcarReloj(w.Header())
+
 		if r.Method != http.MethodPost {
 			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
 			return
@@ -67,15 +92,32 @@ func (s *HTTPServer) Start() error {
 		}
 
 		w.Header().Set("Content-Type", "application/json")
+		marcarReloj(w.Header())
 		json.NewEncoder(w).Encode(respuesta)
 	})
 
 	// Endpoint de healthcheck
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		estado := map[string]interface{}{"status": "ok", "module": s.Nombre}
+		if s.EstadoSalud != nil {
+			for clave, valor := range s.EstadoSalud() {
+				estado[clave] = valor
+			}
+		}
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"status": "ok", "module": s.Nombre})
+		json.NewEncoder(w).Encode(estado)
 	})
 
+	// Rutas del reloj compartido (modo DISCRETO)
+	for patron, handler := range rutasReloj() {
+		mux.HandleFunc(patron, handler)
+	}
+
+	// Rutas propias del módulo
+	for patron, handler := range s.rutas {
+		mux.HandleFunc(patron, handler)
+	}
+
 	// Si ya tiene Listener asignado (caso IO)
 	if s.Listener != nil {
 		slog.Info("Servidor HTTP escuchando", "módulo", s.Nombre, "dirección", s.Listener.Addr().String())
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -16,6 +17,8 @@ type Modulo struct {
 	Clientes    map[string]*HTTPClient
 	ConfigPath  string
 	HandlerFunc map[string]map[string]HTTPHandlerFunc
+	Rutas       map[string]http.HandlerFunc
+	EstadoSalud func() map[string]interface{} // Datos extra para /health (opcional)
 }
 
 // NuevoModulo crea una nueva instancia de un módulo
@@ -25,6 +28,7 @@ func NuevoModulo(nombre string, configPath string) *Modulo {
 		Clientes:    make(map[string]*HTTPClient),
 		ConfigPath:  configPath,
 		HandlerFunc: make(map[string]map[string]HTTPHandlerFunc),
+		Rutas:       make(map[string]http.HandlerFunc),
 	}
 }
 
@@ -36,6 +40,11 @@ func (m *Modulo) RegistrarHandler(tipo string, operacion string, handler HTTPHan
 	m.HandlerFunc[tipo][operacion] = handler
 }
 
+// RegistrarRuta registra un manejador HTTP propio que se agrega al servidor al iniciarlo
+func (m *Modulo) RegistrarRuta(patron string, handler http.HandlerFunc) {
+	m.Rutas[patron] = handler
+}
+
 // IniciarServidor crea e inicializa el servidor HTTP del módulo
 func (m *Modulo) IniciarServidor(ip string, puerto int) {
 	m.Server = NewHTTPServer(ip, puerto, m.Nombre)
@@ -67,6 +76,11 @@ func (m *Modulo) IniciarServidor(ip string, puerto int) {
 		})
 	}
 
+	for patron, handler := range m.Rutas {
+		m.Server.RegistrarRuta(patron, handler)
+	}
+	m.Server.EstadoSalud = m.EstadoSalud
+
 	go func() {
 		err := m.Server.Start()
 		if err != nil {
@@ -116,30 +130,55 @@ func CargarConfiguracion[T any](ruta string) *T {
 	return &config
 }
 
+// LeerConfiguracion decodifica un archivo de configuración devolviendo el error en lugar de terminar el proceso.
+// Se usa para recargar la configuración en caliente
+func LeerConfiguracion[T any](ruta string) (*T, error) {
+	file, err := os.Open(ruta)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo archivo de configuración %s: %w", ruta, err)
+	}
+	defer file.Close()
+
+	var config T
+	if err := json.NewDecoder(file).Decode(&config); err != nil {
+		return nil, fmt.Errorf("error decodificando configuración %s: %w", ruta, err)
+	}
+	return &config, nil
+}
+
 // ============================================================================
 // Constantes para tipos de mensajes entre módulos
 // ============================================================================
 const (
     // === COMUNICACIÓN BÁSICA (1-9) ===
-    MensajeHandshake = 1  // Conexión inicial
-    MensajeOperacion = 2  // Operaciones genéricas
+    MensajeHandshake      = 1  // Conexión inicial
+    MensajeOperacion      = 2  // Operaciones genéricas
+    MensajeBaja           = 3  // Baja de una CPU o IO al cerrarse
+    MensajeBajaCompletada = 4  // La CPU terminó de drenar y puede cerrarse
     
     // === OPERACIONES DE MEMORIA (10-19) ===
-    MensajeLeer         = 10  // Leer datos
-    MensajeEscribir     = 11  // Escribir datos
-    MensajeObtenerMarco = 12  // Obtener marco
-    MensajeFetch        = 13  // Fetch instrucción
-    MensajeEspacioLibre = 14  // Consultar espacio
-    MensajeMemoryDump   = 15  // Volcado memoria
+    MensajeLeer                = 10  // Leer datos
+    MensajeEscribir            = 11  // Escribir datos
+    MensajeObtenerMarco        = 12  // Obtener marco
+    MensajeFetch               = 13  // Fetch instrucción
+    MensajeEspacioLibre        = 14  // Consultar espacio
+    MensajeMemoryDump          = 15  // Volcado memoria
+    MensajeAdjuntarSegmento    = 16  // Crear/adjuntar segmento compartido
+    MensajeDesadjuntarSegmento = 17  // Desadjuntar segmento compartido
     
     // === GESTIÓN DE PROCESOS (20-29) ===
     MensajeInicializarProceso  = 20  // Crear proceso
     MensajeFinalizarProceso    = 21  // Terminar proceso
     MensajeSuspenderProceso    = 22  // Suspender proceso
     MensajeDessuspenderProceso = 23  // Reactivar proceso
+    MensajeCrearHilo           = 24  // Cargar instrucciones de un hilo
+    MensajeFinalizarHilo       = 25  // Descartar instrucciones de un hilo
     
     // === EJECUCIÓN DE CPU (30-39) ===
     MensajeEjecutar           = 30  // Ejecutar en CPU
     MensajeObtenerInstruccion = 31  // Obtener instrucción
     MensajeInterrupcion       = 32  // Interrumpir CPU
+    
+    // === ADMINISTRACIÓN (40-49) ===
+    MensajeRecargarConfiguracion = 40  // Recargar configuración en caliente
 )
\ No newline at end of file
@@ -8,7 +8,7 @@ import (
 // AplicarRetardo aplica un retardo simulado y lo registra
 func AplicarRetardo(operacion string, duracionMs int) {
 	slog.Info("Aplicando retardo", "operación", operacion, "duración_ms", duracionMs)
-	time.Sleep(time.Duration(duracionMs) * time.Millisecond)
+	Dormir(time.Duration(duracionMs) * time.Millisecond)
 	slog.Info("Retardo completado", "operación", operacion)
 }
 
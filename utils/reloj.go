@@ -0,0 +1,297 @@
+package utils
+
+import (
+	"container/heap"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Modos del reloj de simulación
+const (
+	RelojReal     = "REAL"     // El tiempo simulado es el tiempo real
+	RelojEscalado = "ESCALADO" // El tiempo simulado corre FACTOR_RELOJ veces más rápido que el real
+	RelojDiscreto = "DISCRETO" // El tiempo simulado salta al próximo evento cuando todos los módulos quedan inactivos
+)
+
+// ventanaInactividad es el tiempo real sin actividad del reloj tras el cual el modo discreto avanza al próximo evento
+const ventanaInactividad = 5 * time.Millisecond
+
+// Reloj abstrae el paso del tiempo simulado: retardos, timers y mediciones
+type Reloj interface {
+	Ahora() time.Time
+	Dormir(d time.Duration)
+	Programar(d time.Duration, f func()) Temporizador
+}
+
+// Temporizador es una acción programada que se puede cancelar
+type Temporizador interface {
+	// Detener cancela la acción; devuelve false si ya se ejecutó o se había cancelado
+	Detener() bool
+}
+
+var (
+	relojActual Reloj = relojReal{}
+	modoReloj         = RelojReal
+	relojMutex  sync.RWMutex
+)
+
+// ConfigurarReloj selecciona el modo del reloj del módulo. Se llama una vez al iniciar, antes de usarlo
+func ConfigurarReloj(modo string, factor float64) error {
+	modo = strings.ToUpper(modo)
+
+	var reloj Reloj
+	switch modo {
+	case "", RelojReal:
+		modo = RelojReal
+		reloj = relojReal{}
+	case RelojEscalado:
+		if factor <= 0 {
+			return fmt.Errorf("el modo %s requiere FACTOR_RELOJ mayor a 0", RelojEscalado)
+		}
+		reloj = &relojEscalado{base: time.Now(), factor: factor}
+	case RelojDiscreto:
+		reloj = nuevoRelojDiscreto()
+	default:
+		return fmt.Errorf("modo de reloj desconocido: %s", modo)
+	}
+
+	relojMutex.Lock()
+	relojActual = reloj
+	modoReloj = modo
+	relojMutex.Unlock()
+
+	InfoLog.Info("Reloj de simulación configurado", "modo", modo, "factor", factor)
+	return nil
+}
+
+func reloj() Reloj {
+	relojMutex.RLock()
+	defer relojMutex.RUnlock()
+	return relojActual
+}
+
+// ModoReloj devuelve el modo del reloj configurado
+func ModoReloj() string {
+	relojMutex.RLock()
+	defer relojMutex.RUnlock()
+	return modoReloj
+}
+
+// Ahora devuelve el instante actual del tiempo simulado
+func Ahora() time.Time {
+	return reloj().Ahora()
+}
+
+// Desde devuelve el tiempo simulado transcurrido desde t
+func Desde(t time.Time) time.Duration {
+	return Ahora().Sub(t)
+}
+
+// Dormir bloquea durante d de tiempo simulado
+func Dormir(d time.Duration) {
+	reloj().Dormir(d)
+}
+
+// Programar ejecuta f en otra goroutine después de d de tiempo simulado
+func Programar(d time.Duration, f func()) Temporizador {
+	return reloj().Programar(d, f)
+}
+
+// === REAL ===
+
+type relojReal struct{}
+
+func (relojReal) Ahora() time.Time       { return time.Now() }
+func (relojReal) Dormir(d time.Duration) { time.Sleep(d) }
+
+func (relojReal) Programar(d time.Duration, f func()) Temporizador {
+	return temporizadorReal{time.AfterFunc(d, f)}
+}
+
+type temporizadorReal struct {
+	timer *time.Timer
+}
+
+func (t temporizadorReal) Detener() bool { return t.timer.Stop() }
+
+// === ESCALADO ===
+
+type relojEscalado struct {
+	base   time.Time
+	factor float64
+}
+
+func (r *relojEscalado) Ahora() time.Time {
+	transcurrido := time.Since(r.base)
+	return r.base.Add(time.Duration(float64(transcurrido) * r.factor))
+}
+
+func (r *relojEscalado) real(d time.Duration) time.Duration {
+	return time.Duration(float64(d) / r.factor)
+}
+
+func (r *relojEscalado) Dormir(d time.Duration) { time.Sleep(r.real(d)) }
+
+func (r *relojEscalado) Programar(d time.Duration, f func()) Temporizador {
+	return temporizadorReal{time.AfterFunc(r.real(d), f)}
+}
+
+// === DISCRETO ===
+
+// eventoReloj es una acción pendiente del reloj discreto
+type eventoReloj struct {
+	cuando    time.Time
+	secuencia int64 // Desempata eventos simultáneos por orden de programación
+	accion    func()
+	indice    int
+}
+
+type colaEventos []*eventoReloj
+
+func (c colaEventos) Len() int { return len(c) }
+func (c colaEventos) Less(i, j int) bool {
+	if c[i].cuando.Equal(c[j].cuando) {
+		return c[i].secuencia < c[j].secuencia
+	}
+	return c[i].cuando.Before(c[j].cuando)
+}
+func (c colaEventos) Swap(i, j int) {
+	c[i], c[j] = c[j], c[i]
+	c[i].indice = i
+	c[j].indice = j
+}
+func (c *colaEventos) Push(x interface{}) {
+	evento := x.(*eventoReloj)
+	evento.indice = len(*c)
+	*c = append(*c, evento)
+}
+func (c *colaEventos) Pop() interface{} {
+	anterior := *c
+	n := len(anterior)
+	evento := anterior[n-1]
+	*c = anterior[:n-1]
+	evento.indice = -1
+	return evento
+}
+
+// relojDiscreto mantiene el tiempo simulado detenido mientras el sistema trabaja y, cuando pasa
+// ventanaInactividad sin actividad en ningún módulo, salta al próximo evento pendiente.
+// Los eventos simultáneos se disparan en el orden en que se programaron. Es el reloj del kernel;
+// los demás módulos lo usan a través de un relojRemoto
+type relojDiscreto struct {
+	mutex     sync.Mutex
+	ahora     time.Time
+	eventos   colaEventos
+	secuencia int64
+	actividad chan struct{}
+
+	compartidoMutex sync.Mutex
+	participantes   map[string]int           // URL de cada módulo -> consultas fallidas seguidas
+	esperas         map[string]*esperaRemota // Esperas cancelables de otros módulos, por ID
+}
+
+func nuevoRelojDiscreto() *relojDiscreto {
+	r := &relojDiscreto{
+		ahora:         time.Now(),
+		actividad:     make(chan struct{}, 1),
+		participantes: make(map[string]int),
+		esperas:       make(map[string]*esperaRemota),
+	}
+	go r.avanzar()
+	return r
+}
+
+func (r *relojDiscreto) Ahora() time.Time {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.ahora
+}
+
+func (r *relojDiscreto) Dormir(d time.Duration) {
+	defer esperando()()
+	despierto := make(chan struct{})
+	r.Programar(d, func() { close(despierto) })
+	<-despierto
+}
+
+func (r *relojDiscreto) Programar(d time.Duration, f func()) Temporizador {
+	if d < 0 {
+		d = 0
+	}
+
+	r.mutex.Lock()
+	r.secuencia++
+	evento := &eventoReloj{cuando: r.ahora.Add(d), secuencia: r.secuencia, accion: f}
+	heap.Push(&r.eventos, evento)
+	r.mutex.Unlock()
+
+	r.notificarActividad()
+	return &temporizadorDiscreto{reloj: r, evento: evento}
+}
+
+func (r *relojDiscreto) notificarActividad() {
+	actividadReloj.Add(1)
+	select {
+	case r.actividad <- struct{}{}:
+	default:
+	}
+}
+
+// avanzar dispara los eventos pendientes en orden, saltando el tiempo cuando el sistema queda inactivo
+func (r *relojDiscreto) avanzar() {
+	for {
+		// Esperar a que haya eventos y a que el kernel y los demás módulos queden inactivos
+		<-r.actividad
+		for {
+			r.esperarInactividadLocal()
+			if r.sistemaInactivo() {
+				break
+			}
+		}
+
+		r.mutex.Lock()
+		if r.eventos.Len() == 0 {
+			r.mutex.Unlock()
+			continue
+		}
+		siguiente := heap.Pop(&r.eventos).(*eventoReloj)
+		if siguiente.cuando.After(r.ahora) {
+			r.ahora = siguiente.cuando
+		}
+		r.mutex.Unlock()
+
+		go siguiente.accion()
+
+		// El evento disparado puede programar otros: se espera de nuevo a que el sistema quede inactivo
+		r.notificarActividad()
+	}
+}
+
+// esperarInactividadLocal vuelve cuando pasa ventanaInactividad sin que se programen ni disparen eventos
+func (r *relojDiscreto) esperarInactividadLocal() {
+	for {
+		select {
+		case <-r.actividad:
+		case <-time.After(ventanaInactividad):
+			return
+		}
+	}
+}
+
+type temporizadorDiscreto struct {
+	reloj  *relojDiscreto
+	evento *eventoReloj
+}
+
+func (t *temporizadorDiscreto) Detener() bool {
+	t.reloj.mutex.Lock()
+	defer t.reloj.mutex.Unlock()
+
+	if t.evento.indice < 0 {
+		return false
+	}
+	heap.Remove(&t.reloj.eventos, t.evento.indice)
+	return true
+}
@@ -0,0 +1,416 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// En modo DISCRETO hay un solo reloj: el del kernel. Los demás módulos usan un relojRemoto que le pide
+// al kernel cada espera y toma la hora de los mensajes que reciben. Antes de saltar al próximo evento,
+// el kernel consulta a todos los módulos cuántos mensajes enviaron y recibieron y cuántos manejadores
+// siguen trabajando: el reloj avanza solo si no hay mensajes en vuelo, ningún manejador trabaja (los que
+// esperan una respuesta o un retardo no cuentan) y ningún contador cambió durante ventanaInactividad
+
+const (
+	encabezadoReloj      = "X-Reloj" // Hora simulada (ns) del módulo que envía el mensaje
+	fallasMaximasCenso   = 3         // Consultas fallidas seguidas tras las que un módulo deja de contarse
+	timeoutConsultaCenso = time.Second
+	esperaReintentoReloj = time.Second
+)
+
+var (
+	mensajesEnviados  atomic.Int64
+	mensajesRecibidos atomic.Int64
+	actividadReloj    atomic.Int64 // Mensajes y eventos del reloj; si cambia, el módulo no estaba inactivo
+
+	manejadoresActivos atomic.Int64 // Mensajes que el módulo está atendiendo
+	esperasBloqueadas  atomic.Int64 // Llamadas y retardos en curso, que no cuentan como trabajo
+)
+
+// censoReloj es lo que un módulo informa al kernel para decidir si el sistema quedó inactivo
+type censoReloj struct {
+	Enviados  int64 `json:"enviados"`
+	Recibidos int64 `json:"recibidos"`
+	Actividad int64 `json:"actividad"`
+	Ocupados  int64 `json:"ocupados"`
+}
+
+type pedidoReloj struct {
+	URL      string `json:"url,omitempty"`
+	Duracion int64  `json:"duracion_ns,omitempty"`
+	ID       string `json:"id,omitempty"`
+}
+
+type respuestaReloj struct {
+	Ahora     int64 `json:"ahora_ns"`
+	Cancelado bool  `json:"cancelado,omitempty"`
+}
+
+func contarEnvio() {
+	mensajesEnviados.Add(1)
+	actividadReloj.Add(1)
+}
+
+func contarRecepcion() {
+	mensajesRecibidos.Add(1)
+	actividadReloj.Add(1)
+}
+
+// esperando marca una espera bloqueante en curso; la función devuelta la da por terminada
+func esperando() func() {
+	esperasBloqueadas.Add(1)
+	return func() { esperasBloqueadas.Add(-1) }
+}
+
+func censoLocal() censoReloj {
+	censo := censoReloj{Enviados: mensajesEnviados.Load(), Recibidos: mensajesRecibidos.Load(), Actividad: actividadReloj.Load()}
+	// Cada manejador bloqueado tiene al menos una espera en curso
+	if ocupados := manejadoresActivos.Load() - esperasBloqueadas.Load(); ocupados > 0 {
+		censo.Ocupados = ocupados
+	}
+	return censo
+}
+
+// marcarReloj agrega al mensaje la hora simulada del módulo que lo envía
+func marcarReloj(encabezados http.Header) {
+	if ModoReloj() == RelojDiscreto {
+		encabezados.Set(encabezadoReloj, strconv.FormatInt(Ahora().UnixNano(), 10))
+	}
+}
+
+// sincronizarReloj adelanta el reloj remoto a la hora que trae el mensaje recibido
+func sincronizarReloj(encabezados http.Header) {
+	remoto, esRemoto := reloj().(*relojRemoto)
+	if !esRemoto {
+		return
+	}
+	if ns, err := strconv.ParseInt(encabezados.Get(encabezadoReloj), 10, 64); err == nil {
+		remoto.sincronizar(time.Unix(0, ns))
+	}
+}
+
+// rutasReloj devuelve las rutas HTTP que necesita el reloj del módulo según su rol
+func rutasReloj() map[string]http.HandlerFunc {
+	switch r := reloj().(type) {
+	case *relojDiscreto:
+		return map[string]http.HandlerFunc{
+			"POST /reloj/registrar": r.manejarRegistro,
+			"POST /reloj/dormir":    r.manejarDormir,
+			"POST /reloj/cancelar":  r.manejarCancelacion,
+		}
+	case *relojRemoto:
+		return map[string]http.HandlerFunc{
+			"GET /reloj/estado": func(w http.ResponseWriter, req *http.Request) {
+				ResponderJSON(w, http.StatusOK, censoLocal())
+			},
+		}
+	}
+	return nil
+}
+
+// ConfigurarRelojCompartido configura el reloj de un módulo que no es el kernel. En modo DISCRETO usa el
+// reloj del kernel y se anuncia con su propia dirección para que el kernel le consulte si está inactivo
+func ConfigurarRelojCompartido(modo string, factor float64, ipKernel string, puertoKernel int, ipPropia string, puertoPropio int) error {
+	if !strings.EqualFold(modo, RelojDiscreto) {
+		return ConfigurarReloj(modo, factor)
+	}
+	if ipKernel == "" || puertoKernel <= 0 {
+		return fmt.Errorf("el modo %s requiere IP_KERNEL y PUERTO_KERNEL", RelojDiscreto)
+	}
+
+	remoto := &relojRemoto{
+		maestro: fmt.Sprintf("http://%s:%d", ipKernel, puertoKernel),
+		propia:  fmt.Sprintf("http://%s:%d", ipPropia, puertoPropio),
+		cliente: &http.Client{}, // Sin timeout: una espera dura lo que tarde el reloj en llegar
+		ahora:   time.Now(),
+	}
+
+	relojMutex.Lock()
+	relojActual = remoto
+	modoReloj = RelojDiscreto
+	relojMutex.Unlock()
+
+	go remoto.registrarse()
+	InfoLog.Info("Reloj de simulación configurado", "modo", RelojDiscreto, "kernel", remoto.maestro)
+	return nil
+}
+
+// === KERNEL ===
+
+// esperaRemota es una espera de otro módulo programada en el reloj del kernel
+type esperaRemota struct {
+	temporizador Temporizador
+	cancelada    chan struct{}
+}
+
+func (r *relojDiscreto) manejarRegistro(w http.ResponseWriter, req *http.Request) {
+	var pedido pedidoReloj
+	if err := json.NewDecoder(req.Body).Decode(&pedido); err != nil || pedido.URL == "" {
+		http.Error(w, "registro de reloj inválido", http.StatusBadRequest)
+		return
+	}
+
+	r.compartidoMutex.Lock()
+	r.participantes[pedido.URL] = 0
+	r.compartidoMutex.Unlock()
+
+	InfoLog.Info("Módulo sumado al reloj compartido", "url", pedido.URL)
+	ResponderJSON(w, http.StatusOK, respuestaReloj{Ahora: r.Ahora().UnixNano()})
+}
+
+// manejarDormir responde cuando el reloj llega al fin de la espera o cuando se la cancela
+func (r *relojDiscreto) manejarDormir(w http.ResponseWriter, req *http.Request) {
+	contarRecepcion()
+
+	var pedido pedidoReloj
+	if err := json.NewDecoder(req.Body).Decode(&pedido); err != nil {
+		contarEnvio()
+		http.Error(w, "espera de reloj inválida", http.StatusBadRequest)
+		return
+	}
+
+	despierto := make(chan struct{})
+	espera := &esperaRemota{cancelada: make(chan struct{})}
+	espera.temporizador = r.Programar(time.Duration(pedido.Duracion), func() {
+		contarEnvio() // La respuesta queda en vuelo hasta que el módulo la reciba
+		close(despierto)
+	})
+	if pedido.ID != "" {
+		r.compartidoMutex.Lock()
+		r.esperas[pedido.ID] = espera
+		r.compartidoMutex.Unlock()
+		defer func() {
+			r.compartidoMutex.Lock()
+			delete(r.esperas, pedido.ID)
+			r.compartidoMutex.Unlock()
+		}()
+	}
+
+	cancelado := false
+	select {
+	case <-despierto:
+	case <-espera.cancelada:
+		cancelado = true
+	case <-req.Context().Done():
+		if espera.temporizador.Detener() {
+			contarEnvio()
+		}
+		return
+	}
+	ResponderJSON(w, http.StatusOK, respuestaReloj{Ahora: r.Ahora().UnixNano(), Cancelado: cancelado})
+}
+
+func (r *relojDiscreto) manejarCancelacion(w http.ResponseWriter, req *http.Request) {
+	contarRecepcion()
+	defer contarEnvio()
+
+	var pedido pedidoReloj
+	if err := json.NewDecoder(req.Body).Decode(&pedido); err != nil {
+		http.Error(w, "cancelación de reloj inválida", http.StatusBadRequest)
+		return
+	}
+
+	r.compartidoMutex.Lock()
+	espera := r.esperas[pedido.ID]
+	r.compartidoMutex.Unlock()
+
+	cancelado := espera != nil && espera.temporizador.Detener()
+	if cancelado {
+		contarEnvio() // Respuesta de la espera cancelada
+		close(espera.cancelada)
+	}
+	ResponderJSON(w, http.StatusOK, respuestaReloj{Ahora: r.Ahora().UnixNano(), Cancelado: cancelado})
+}
+
+// sistemaInactivo suma los contadores de todos los módulos dos veces, separadas por ventanaInactividad.
+// El sistema está inactivo si no hay mensajes en vuelo y nada cambió entre las dos consultas
+func (r *relojDiscreto) sistemaInactivo() bool {
+	antes, ok := r.censo()
+	if !ok || antes.Enviados != antes.Recibidos || antes.Ocupados > 0 {
+		return false
+	}
+	time.Sleep(ventanaInactividad)
+	despues, ok := r.censo()
+	return ok && antes == despues
+}
+
+func (r *relojDiscreto) censo() (censoReloj, bool) {
+	total := censoLocal()
+
+	r.compartidoMutex.Lock()
+	participantes := make([]string, 0, len(r.participantes))
+	for url := range r.participantes {
+		participantes = append(participantes, url)
+	}
+	r.compartidoMutex.Unlock()
+
+	completo := true
+	for _, url := range participantes {
+		parcial, err := consultarCenso(url)
+
+		r.compartidoMutex.Lock()
+		if err != nil {
+			r.participantes[url]++
+			if r.participantes[url] >= fallasMaximasCenso {
+				delete(r.participantes, url)
+				ErrorLog.Error("Módulo sin respuesta, deja de contarse en el reloj compartido", "url", url, "error", err)
+			} else {
+				completo = false
+			}
+		} else {
+			r.participantes[url] = 0
+		}
+		r.compartidoMutex.Unlock()
+
+		total.Enviados += parcial.Enviados
+		total.Recibidos += parcial.Recibidos
+		total.Actividad += parcial.Actividad
+		total.Ocupados += parcial.Ocupados
+	}
+	return total, completo
+}
+
+func consultarCenso(url string) (censoReloj, error) {
+	cliente := http.Client{Timeout: timeoutConsultaCenso}
+	resp, err := cliente.Get(url + "/reloj/estado")
+	if err != nil {
+		return censoReloj{}, err
+	}
+	defer resp.Body.Close()
+
+	var censo censoReloj
+	if err := json.NewDecoder(resp.Body).Decode(&censo); err != nil {
+		return censoReloj{}, err
+	}
+	return censo, nil
+}
+
+// === REMOTO ===
+
+const (
+	temporizadorPendiente int32 = iota
+	temporizadorDisparado
+	temporizadorCancelado
+)
+
+// relojRemoto delega las esperas en el reloj del kernel. Su hora es la última que recibió: el reloj del
+// kernel solo avanza con todos los módulos inactivos, y cada mensaje o fin de espera trae la hora nueva
+type relojRemoto struct {
+	maestro   string
+	propia    string
+	cliente   *http.Client
+	mutex     sync.Mutex
+	ahora     time.Time
+	secuencia atomic.Int64
+}
+
+func (r *relojRemoto) Ahora() time.Time {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.ahora
+}
+
+func (r *relojRemoto) sincronizar(ahora time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if ahora.After(r.ahora) {
+		r.ahora = ahora
+	}
+}
+
+func (r *relojRemoto) Dormir(d time.Duration) {
+	r.esperar(d, "")
+}
+
+func (r *relojRemoto) Programar(d time.Duration, f func()) Temporizador {
+	t := &temporizadorRemoto{reloj: r, id: fmt.Sprintf("%s#%d", r.propia, r.secuencia.Add(1))}
+	go func() {
+		if cancelado := r.esperar(d, t.id); cancelado {
+			return
+		}
+		if t.estado.CompareAndSwap(temporizadorPendiente, temporizadorDisparado) {
+			f()
+		}
+	}()
+	return t
+}
+
+// esperar bloquea hasta que el reloj del kernel avance d y devuelve si la espera se canceló
+func (r *relojRemoto) esperar(d time.Duration, id string) bool {
+	if d < 0 {
+		d = 0
+	}
+	for {
+		var respuesta respuestaReloj
+		err := r.llamar("/reloj/dormir", pedidoReloj{Duracion: int64(d), ID: id}, &respuesta, true)
+		if err == nil {
+			r.sincronizar(time.Unix(0, respuesta.Ahora))
+			return respuesta.Cancelado
+		}
+		ErrorLog.Error("No se pudo esperar en el reloj del kernel, reintentando", "error", err)
+		time.Sleep(esperaReintentoReloj)
+	}
+}
+
+// registrarse se anuncia al kernel para que lo cuente antes de avanzar el reloj
+func (r *relojRemoto) registrarse() {
+	for {
+		var respuesta respuestaReloj
+		if err := r.llamar("/reloj/registrar", pedidoReloj{URL: r.propia}, &respuesta, false); err == nil {
+			r.mutex.Lock()
+			r.ahora = time.Unix(0, respuesta.Ahora)
+			r.mutex.Unlock()
+			InfoLog.Info("Reloj compartido con el kernel", "kernel", r.maestro)
+			return
+		}
+		time.Sleep(esperaReintentoReloj)
+	}
+}
+
+// llamar envía un pedido al reloj del kernel. Con contar, el pedido y su respuesta cuentan como mensajes
+func (r *relojRemoto) llamar(ruta string, pedido pedidoReloj, respuesta *respuestaReloj, contar bool) error {
+	cuerpo, err := json.Marshal(pedido)
+	if err != nil {
+		return err
+	}
+
+	if contar {
+		contarEnvio()
+		defer contarRecepcion()
+		defer esperando()()
+	}
+	resp, err := r.cliente.Post(r.maestro+ruta, "application/json", bytes.NewBuffer(cuerpo))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("respuesta HTTP no exitosa del reloj: %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(respuesta)
+}
+
+type temporizadorRemoto struct {
+	reloj  *relojRemoto
+	id     string
+	estado atomic.Int32
+}
+
+func (t *temporizadorRemoto) Detener() bool {
+	if !t.estado.CompareAndSwap(temporizadorPendiente, temporizadorCancelado) {
+		return false
+	}
+	var respuesta respuestaReloj
+	if err := t.reloj.llamar("/reloj/cancelar", pedidoReloj{ID: t.id}, &respuesta, true); err != nil {
+		ErrorLog.Error("No se pudo cancelar la espera en el reloj del kernel", "id", t.id, "error", err)
+	}
+	return true
+}
@@ -1,8 +1,13 @@
 package utils
 
-// Semaforo implementa un semáforo contador con canales
+import "sync"
+
+// Semaforo implementa un semáforo contador redimensionable
 type Semaforo struct {
-	c chan struct{}
+	mutex     sync.Mutex
+	cond      *sync.Cond
+	capacidad int
+	enUso     int
 }
 
 // NewSemaforo crea un semáforo con capacidad inicial
@@ -10,31 +15,63 @@ func NewSemaforo(capacidad int) *Semaforo {
 	if capacidad <= 0 {
 		capacidad = 1
 	}
-	return &Semaforo{
-		c: make(chan struct{}, capacidad),
-	}
+	s := &Semaforo{capacidad: capacidad}
+	s.cond = sync.NewCond(&s.mutex)
+	return s
 }
 
 // Wait (P) decrementa el semáforo, bloquea si es 0
 func (s *Semaforo) Wait() {
-	s.c <- struct{}{}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for s.enUso >= s.capacidad {
+		s.cond.Wait()
+	}
+	s.enUso++
 }
 
 // Signal (V) incrementa el semáforo
 func (s *Semaforo) Signal() {
-	select {
-	case <-s.c:
-	default:
-		// Capacidad completa, no hace nada para prevenir incremento excesivo
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.enUso > 0 {
+		// Sin lugares tomados no hace nada para prevenir incremento excesivo
+		s.enUso--
 	}
+	s.cond.Signal()
 }
 
 // TryWait intenta decrementar sin bloquear
 func (s *Semaforo) TryWait() bool {
-	select {
-	case s.c <- struct{}{}:
-		return true
-	default:
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.enUso >= s.capacidad {
 		return false
 	}
-}
\ No newline at end of file
+	s.enUso++
+	return true
+}
+
+// Redimensionar cambia la capacidad. Si se achica por debajo de los lugares tomados,
+// los nuevos Wait bloquean hasta que se liberen suficientes lugares
+func (s *Semaforo) Redimensionar(capacidad int) {
+	if capacidad <= 0 {
+		capacidad = 1
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.capacidad = capacidad
+	s.cond.Broadcast()
+}
+
+// Estado devuelve los lugares tomados y la capacidad actual
+func (s *Semaforo) Estado() (enUso int, capacidad int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.enUso, s.capacidad
+}